@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// argSpec represents a positional argument specification.
+type argSpec struct {
+	names []string
+	min   int
+	max   int
+}
+
+// validate returns an error if args do not satisfy the
+// specification. The first double hyphen is not counted.
+func (s *argSpec) validate(args []string) error {
+	if s == nil {
+		return nil
+	}
+	for i, arg := range args {
+		if arg == "--" {
+			args = append(args[:i:i], args[i+1:]...)
+			break
+		}
+	}
+	if len(args) < s.min {
+		return ErrMissingArg(s.name(len(args)))
+	}
+	if s.max >= 0 && len(args) > s.max {
+		return ErrUnexpectedArg(args[s.max])
+	}
+	return nil
+}
+
+// name returns the name of the positional argument at index i.
+func (s *argSpec) name(i int) string {
+	if i < len(s.names) {
+		return s.names[i]
+	}
+	if len(s.names) > 0 && s.max < 0 {
+		return s.names[len(s.names)-1]
+	}
+	return fmt.Sprintf("arg%d", i+1)
+}
+
+// synopsis returns the positional arguments for help output.
+// Required arguments are wrapped in angle brackets, optional
+// arguments in square brackets and variadic arguments end with
+// an ellipsis.
+func (s *argSpec) synopsis() string {
+	n := s.max
+	if n < 0 {
+		n = s.min
+		if len(s.names) > n {
+			n = len(s.names)
+		}
+		if n == 0 {
+			n = 1
+		}
+	}
+	parts := make([]string, n)
+	for i := range parts {
+		if i < s.min {
+			parts[i] = "<" + s.name(i) + ">"
+		} else {
+			parts[i] = "[" + s.name(i) + "]"
+		}
+	}
+	if s.max < 0 {
+		parts[n-1] += "..."
+	}
+	return strings.Join(parts, " ")
+}
+
+// Args sets the named positional arguments. Names wrapped in square
+// brackets are optional and must follow all required arguments. The
+// last name may end with an ellipsis to accept any number of values.
+// An ellipsis on a required name requires at least one value.
+//
+//	Args("src", "dst")
+//	Args("name", "[version]")
+//	Args("file...")
+//	Args("[file...]")
+func Args(names ...string) CommandOption {
+	s := &argSpec{names: make([]string, len(names))}
+	for i, name := range names {
+		optional := strings.HasPrefix(name, "[") && strings.HasSuffix(name, "]")
+		if optional {
+			name = name[1 : len(name)-1]
+		} else {
+			if s.min != i {
+				panic(fmt.Errorf("cli: required argument '%s' follows optional argument", name))
+			}
+			s.min++
+		}
+		s.max = i + 1
+		if strings.HasSuffix(name, "...") {
+			if i != len(names)-1 {
+				panic(fmt.Errorf("cli: variadic argument '%s' must be last", name))
+			}
+			name = strings.TrimSuffix(name, "...")
+			s.max = -1
+		}
+		s.names[i] = name
+	}
+	return withArgs(s)
+}
+
+// ExactArgs requires exactly n positional arguments.
+func ExactArgs(n int) CommandOption {
+	return RangeArgs(n, n)
+}
+
+// MinArgs requires at least n positional arguments.
+func MinArgs(n int) CommandOption {
+	return RangeArgs(n, -1)
+}
+
+// MaxArgs allows at most n positional arguments.
+func MaxArgs(n int) CommandOption {
+	return RangeArgs(0, n)
+}
+
+// RangeArgs requires between min and max positional
+// arguments, inclusive. A negative max is unbounded.
+// RangeArgs panics if min is negative or greater than max.
+func RangeArgs(min, max int) CommandOption {
+	if min < 0 || (max >= 0 && min > max) {
+		panic(fmt.Errorf("cli: invalid argument range %d to %d", min, max))
+	}
+	return withArgs(&argSpec{min: min, max: max})
+}
+
+// withArgs sets the positional argument specification.
+func withArgs(s *argSpec) CommandOption {
+	return func(c *Command) {
+		c.args = s
+	}
+}
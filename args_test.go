@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestArgs(t *testing.T) {
+	var tests = []struct {
+		opt  CommandOption
+		line string
+		want error
+	}{
+		{Args("src", "dst"), "a b", nil},
+		{Args("src", "dst"), "a", ErrMissingArg("dst")},
+		{Args("src", "dst"), "a b c", ErrUnexpectedArg("c")},
+		{Args("name", "[version]"), "a", nil},
+		{Args("name", "[version]"), "a b", nil},
+		{Args("name", "[version]"), "", ErrMissingArg("name")},
+		{Args("name", "[version]"), "a b c", ErrUnexpectedArg("c")},
+		{Args("file..."), "", ErrMissingArg("file")},
+		{Args("file..."), "a b c", nil},
+		{Args("[file...]"), "", nil},
+		{Args("dst", "src..."), "a", ErrMissingArg("src")},
+		{ExactArgs(1), "", ErrMissingArg("arg1")},
+		{ExactArgs(1), "-- a", nil},
+		{MinArgs(2), "a", ErrMissingArg("arg2")},
+		{MaxArgs(1), "a b", ErrUnexpectedArg("b")},
+		{RangeArgs(1, 2), "a b", nil},
+	}
+	for i, tt := range tests {
+		var have []string
+		app := New("appname", newTestUsage(t), nil, Stderr(io.Discard))
+		app.Add("test", func(args []string) error {
+			have = args
+			return nil
+		}, nil, tt.opt)
+		args := append([]string{"appname", "test"}, strings.Fields(tt.line)...)
+		err := app.Run(args)
+		if !reflect.DeepEqual(err, tt.want) {
+			t.Fatalf("%d. Run(%q) error\nhave %v\nwant %v", i, tt.line, err, tt.want)
+		}
+		if err == nil && strings.Join(have, " ") != tt.line {
+			t.Fatalf("%d. Run(%q) args\nhave %v", i, tt.line, have)
+		}
+	}
+}
+
+func TestArgsPanic(t *testing.T) {
+	tests := [][]string{
+		{"[a]", "b"},
+		{"a...", "b"},
+	}
+	for _, names := range tests {
+		func() {
+			defer func() {
+				perr := recover()
+				if perr == nil {
+					t.Fatalf("Args(%q) should panic", names)
+				}
+			}()
+			Args(names...)
+		}()
+	}
+}
+
+func TestRangeArgsPanic(t *testing.T) {
+	tests := [][2]int{
+		{3, 1},
+		{-1, 2},
+		{-1, -1},
+	}
+	for _, tt := range tests {
+		func() {
+			defer func() {
+				perr := recover()
+				if perr == nil {
+					t.Fatalf("RangeArgs(%d, %d) should panic", tt[0], tt[1])
+				}
+			}()
+			RangeArgs(tt[0], tt[1])
+		}()
+	}
+}
@@ -3,6 +3,7 @@ package cli
 
 import (
 	"bufio"
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -10,7 +11,6 @@ import (
 	"os"
 	"sort"
 	"strings"
-	"unicode"
 )
 
 var (
@@ -21,13 +21,14 @@ var (
 // CLI represents a command line application.
 type CLI struct {
 	name           string
+	description    string
 	prefix         string
 	usage          fs.FS
 	scope          string
 	flags          []*Flag
 	flagsMap       map[string]*Flag
 	commands       map[string]*Command
-	middleware     []func(Handler) Handler
+	middleware     []func(ContextHandler) ContextHandler
 	version        string
 	stdin          io.Reader
 	stdout         io.Writer
@@ -35,6 +36,20 @@ type CLI struct {
 	helpHandler    Handler
 	defaultHandler Handler
 	resolve        func(err error)
+	lookupEnv      func(key string) (string, bool)
+	exit           func(code int)
+	exitCodes      []exitCode
+	posix          bool
+	config         config
+	configFile     string
+	configName     string
+	configPath     string
+	debugFlags     bool
+	templateUsage  bool
+	markdownUsage  bool
+	catalog        Catalog
+	pager          bool
+	noPager        bool
 }
 
 // New returns a new CLI application.
@@ -76,40 +91,83 @@ func New(name string, usage fs.FS, flags []*Flag, opts ...Option) *CLI {
 	if c.resolve == nil {
 		c.resolve = c.defaultResolver
 	}
-	c.Add("help", c.helpHandler, nil)
+	if c.lookupEnv == nil {
+		c.lookupEnv = os.LookupEnv
+	}
+	if c.exit == nil {
+		c.exit = os.Exit
+	}
+	if c.configName != "" {
+		usage := Usage(c.catalog.sprintf("Path to the configuration file."))
+		f := NewFlag(configFlagName, &c.configPath, usage)
+		c.flags = append(c.flags[:len(c.flags):len(c.flags)], f)
+	}
+	if c.pager {
+		usage := Usage(c.catalog.sprintf("Do not pipe help output into a pager."))
+		f := NewFlag(noPagerFlagName, &c.noPager, usage)
+		c.flags = append(c.flags[:len(c.flags):len(c.flags)], f)
+	}
+	if c.debugFlags {
+		usage := Usage(c.catalog.sprintf("Print flag values and their sources before running."))
+		f := NewFlag(debugFlagName, new(bool), usage)
+		c.flags = append(c.flags[:len(c.flags):len(c.flags)], f)
+	}
+	help := Description(c.catalog.sprintf("Show usage information for a command."))
+	c.Add("help", c.helpHandler, nil, help).builtin = true
 	if c.version != "" {
-		c.Add("version", c.versionHandler, nil)
+		version := Description(c.catalog.sprintf("Show the application version."))
+		c.Add("version", c.versionHandler, nil, version).builtin = true
 	}
 	return c
 }
 
 // Add adds a new command.
 func (c *CLI) Add(name string, handler Handler, flags []*Flag, opts ...CommandOption) *Command {
-	name = strings.ToLower(name)
-	if handler == nil {
-		panic(fmt.Errorf("cli: command '%s' has nil handler", name))
-	}
-	_, ok := c.commands[name]
-	if ok {
-		panic(fmt.Errorf("cli: duplicate command '%s'", name))
-	}
-	opt := WithMiddleware(c.middleware...)
+	cmd := c.AddContext(name, withContext(handler), flags, opts...)
+	cmd.context = false
+	return cmd
+}
+
+// AddContext adds a new command with a context aware handler.
+func (c *CLI) AddContext(name string, handler ContextHandler, flags []*Flag, opts ...CommandOption) *Command {
+	opt := WithContextMiddleware(c.middleware...)
 	opts = append([]CommandOption{opt}, opts...)
-	cmd := NewCommand(name, handler, flags, opts...)
-	c.commands[name] = cmd
-	if cmd.alias != "" {
-		dup, ok := c.commands[cmd.alias]
-		if ok {
-			panic(fmt.Errorf("cli: duplicate command alias '%s' for '%s'", cmd.alias, dup.name))
-		}
-		c.commands[cmd.alias] = cmd
-	}
+	cmd := addCommand(c.commands, "", name, handler, flags, opts)
+	cmd.context = true
 	return cmd
 }
 
 // Run parses the command line arguments, starting with the
 // program name, and dispatches to the appropriate handler.
+// Run may be called repeatedly. Flags are restored to their
+// default values before each run. Concurrent runs of the same
+// application are not supported.
 func (c *CLI) Run(args []string) error {
+	return c.RunContext(context.Background(), args)
+}
+
+// RunContext is like Run but dispatches with a context derived
+// from ctx. When dispatching to a context aware handler, the context
+// is cancelled on the first interrupt or termination signal and a
+// second signal exits immediately. Signals are left untouched for
+// handlers that are not context aware.
+func (c *CLI) RunContext(ctx context.Context, args []string) error {
+	_, err := c.execute(ctx, args)
+	return err
+}
+
+// Main runs the application with os.Args and exits with the status
+// of the error returned by the handler, as determined by ExitCode and
+// any codes registered with ExitCodeFor. Errors are resolved before exit.
+func (c *CLI) Main() {
+	code, _ := c.execute(context.Background(), nil)
+	c.exit(code)
+}
+
+// execute runs the application and returns the exit status along
+// with the error returned by RunContext. The status is determined
+// before usage errors are rewritten as ErrExitFailure.
+func (c *CLI) execute(ctx context.Context, args []string) (int, error) {
 	if args == nil {
 		args = os.Args
 	} else if len(args) == 0 {
@@ -118,53 +176,185 @@ func (c *CLI) Run(args []string) error {
 	for len(args) > 1 && args[len(args)-1] == "" {
 		args = args[:len(args)-1]
 	}
-	name, err := c.run(args)
-	if err != nil {
-		if errors.Is(err, ErrUsage) {
-			uerr := c.Usage(c.stderr, name)
-			if uerr != nil {
-				return uerr
-			}
-			return ErrExitFailure
-		} else if !errors.Is(err, ErrExitFailure) {
-			c.resolve(err)
+	name, err := c.run(ctx, args)
+	code := c.exitCode(err)
+	if err == nil {
+		return code, nil
+	}
+	var e ExitError
+	if errors.Is(err, ErrUsage) {
+		uerr := c.Usage(c.stderr, name)
+		if uerr != nil {
+			return c.exitCode(uerr), uerr
+		}
+		err = ErrExitFailure
+	} else if errors.Is(err, ErrExitFailure) {
+		if err == errCommandNotFound {
+			err = ErrExitFailure
 		}
+	} else if !errors.As(err, &e) || e.Err != nil {
+		c.resolve(err)
 	}
-	return err
+	return code, err
 }
 
-// run parses the root command and dispatches to the given subcommand.
-func (c *CLI) run(args []string) (string, error) {
-	args, err := c.parse(args, c.flags)
+// run parses the root command and walks down the command tree,
+// parsing flags at each level, and dispatches to the last command
+// found. The returned string is the path of the last command.
+func (c *CLI) run(ctx context.Context, args []string) (string, error) {
+	err := c.reset()
+	if err != nil {
+		return "", err
+	}
+	args, err = c.parse(c.parser(), "", args, c.flags)
+	if err != nil {
+		return "", err
+	}
+	c.config, c.configFile, err = c.readConfig()
+	if err != nil {
+		return "", err
+	}
+	err = c.config.apply(c.configFile, "", c.flags)
 	if err != nil {
 		return "", err
 	}
 	if len(args) < 1 {
+		err = c.checkFlags(c.flags, nil, false)
+		if err != nil {
+			return "", err
+		}
 		return "", c.defaultHandler(args)
 	}
-	name := args[0]
-	cmd, ok := c.commands[name]
+	cmd, ok := c.commands[args[0]]
 	if !ok {
-		return "", c.commandNotFound(name)
+		return "", c.commandNotFound(nil, args[0])
+	}
+	flags := c.flags[:len(c.flags):len(c.flags)]
+	var groups []flagGroup
+	for !cmd.proxy {
+		p := c.parser()
+		p.interspersed = cmd.interspersed && len(cmd.commands) == 0
+		args, err = c.parse(p, cmd.path, args, cmd.flags)
+		if err != nil {
+			return cmd.path, err
+		}
+		flags = append(flags, cmd.flags...)
+		groups = append(groups, cmd.groups...)
+		if len(cmd.commands) == 0 || len(args) < 1 {
+			break
+		}
+		sub, ok := cmd.commands[args[0]]
+		if !ok {
+			return cmd.path, c.commandNotFound(cmd, args[0])
+		}
+		cmd = sub
 	}
 	if cmd.proxy {
 		args = args[1:]
-	} else {
-		args, err = c.parse(args, cmd.flags)
+	}
+	err = c.checkFlags(flags, groups, cmd.builtin)
+	if err != nil {
+		return cmd.path, err
+	}
+	if cmd.context {
+		var stop context.CancelFunc
+		ctx, stop = notifyContext(ctx, c.exit)
+		defer stop()
+	}
+	return cmd.path, cmd.dispatch(ctx, args)
+}
+
+// checkFlags writes the flag sources table if requested and checks
+// the required flags and flag groups unless the command is built in.
+func (c *CLI) checkFlags(flags []*Flag, groups []flagGroup, builtin bool) error {
+	if c.debugFlags && c.flagsMap[debugFlagName].IsSet() {
+		err := writeSources(c.stderr, flags)
+		if err != nil {
+			return err
+		}
+	}
+	if builtin {
+		return nil
+	}
+	err := checkRequired(flags)
+	if err != nil {
+		return err
+	}
+	return checkGroups(groups)
+}
+
+// reset restores every flag in the command tree to its default
+// value and clears the flag map so that each run starts afresh.
+func (c *CLI) reset() error {
+	c.flagsMap = make(map[string]*Flag)
+	c.config, c.configFile = nil, ""
+	return restoreFlags(c.flags, c.commands)
+}
+
+// restoreFlags restores flags and the flags of commands recursively.
+func restoreFlags(flags []*Flag, commands map[string]*Command) error {
+	for _, f := range flags {
+		err := f.restore()
+		if err != nil {
+			return err
+		}
+	}
+	for name, cmd := range commands {
+		if name != cmd.name {
+			continue
+		}
+		err := restoreFlags(cmd.flags, cmd.commands)
 		if err != nil {
-			return name, err
+			return err
 		}
 	}
-	return name, cmd.handler(args)
+	return nil
+}
+
+// lookup returns the command at the slash separated
+// path or nil if no such command exists.
+func (c *CLI) lookup(path string) *Command {
+	var cmd *Command
+	commands := c.commands
+	for _, name := range strings.Split(path, "/") {
+		next, ok := commands[name]
+		if !ok {
+			return nil
+		}
+		cmd, commands = next, next.commands
+	}
+	return cmd
+}
+
+// parser returns a new flag parser with the application configuration.
+func (c *CLI) parser() *parser {
+	return &parser{posix: c.posix, lookupEnv: c.lookupEnv}
+}
+
+// getenv returns the value of the environment variable key
+// as found by the configured environment lookup.
+func (c *CLI) getenv(key string) string {
+	value, _ := c.lookupEnv(key)
+	return value
 }
 
 // parse processes args as flags until there are no longer flags.
-func (c *CLI) parse(args []string, flags []*Flag) ([]string, error) {
+// Values from the configuration file section for the command path
+// are loaded before environment variables and the command line.
+func (c *CLI) parse(p *parser, path string, args []string, flags []*Flag) ([]string, error) {
 	err := c.initFlags(flags)
 	if err != nil {
 		return nil, err
 	}
-	return Parse(args[1:], flags)
+	args, err = p.parse(args[1:], flags)
+	if err != nil {
+		return nil, err
+	}
+	err = c.config.apply(c.configFile, path, flags)
+	if err != nil {
+		return nil, err
+	}
+	return args, nil
 }
 
 // initFlags populates the application flag map and
@@ -173,30 +363,46 @@ func (c *CLI) initFlags(flags []*Flag) error {
 	for _, f := range flags {
 		_, ok := c.flagsMap[f.name]
 		if ok {
-			return fmt.Errorf("Duplicate flag '%s'.", f.name)
+			return errors.New(c.catalog.sprintf("Duplicate flag '%s'.", f.name))
 		}
 		c.flagsMap[f.name] = f
 		if f.alias != "" {
 			_, ok := c.flagsMap[f.alias]
 			if ok {
-				return fmt.Errorf("Duplicate short flag '%s' for '%s'.", f.alias, f.name)
+				return errors.New(c.catalog.sprintf("Duplicate short flag '%s' for '%s'.", f.alias, f.name))
 			}
 			c.flagsMap[f.alias] = f
 		}
 		if f.envKey == "" {
-			key := strings.ToUpper(c.prefix + "_" + f.name)
-			f.envKey = mapper.Replace(key)
+			f.envKey = c.envKey(f.name)
 		}
 	}
 	return nil
 }
 
+// envKey returns the default environment variable key for the flag name.
+func (c *CLI) envKey(name string) string {
+	key := strings.ToUpper(c.prefix + "_" + name)
+	return mapper.Replace(key)
+}
+
 // commandNotFound prints helpful usage information and suggestions.
-func (c *CLI) commandNotFound(name string) error {
-	c.Errorf("Unknown command '%s'.\n", name)
-	c.Errorf("Run '%s help' for usage information.\n", c.name)
+// The parent is the command whose subcommands are considered or nil
+// for the top level commands.
+func (c *CLI) commandNotFound(parent *Command, name string) error {
+	topic := c.name + " help"
+	commands := c.commands
+	if parent != nil {
+		topic += " " + strings.ReplaceAll(parent.path, "/", " ")
+		commands = parent.commands
+	}
+	c.errorln("Unknown command '%s'.", name)
+	c.errorln("Run '%s' for usage information.", topic)
 	similar := make([]string, 0)
-	for _, cmd := range c.commands {
+	for key, cmd := range commands {
+		if key != cmd.name {
+			continue
+		}
 		distance := 0
 		if !strings.HasPrefix(cmd.name, name) {
 			distance = levenshtein(name, cmd.name)
@@ -207,17 +413,27 @@ func (c *CLI) commandNotFound(name string) error {
 	}
 	if len(similar) > 0 {
 		sort.Strings(similar)
-		c.Errorf("\nDid you mean?\n\n")
+		c.Errorf("\n")
+		c.errorln("Did you mean?")
+		c.Errorf("\n")
 		for _, name := range similar {
 			c.Errorf("    %s\n", name)
 		}
 		c.Errorf("\n")
 	}
-	return ErrExitFailure
+	return errCommandNotFound
 }
 
 // Use appends middleware to the global middleware stack.
+// Middleware applies to commands added after it is used.
 func (c *CLI) Use(middleware ...func(Handler) Handler) {
+	c.UseContext(contextMiddleware(middleware...)...)
+}
+
+// UseContext appends context aware middleware to the global
+// middleware stack. Middleware applies to commands added
+// after it is used.
+func (c *CLI) UseContext(middleware ...func(ContextHandler) ContextHandler) {
 	c.middleware = append(c.middleware, middleware...)
 }
 
@@ -231,6 +447,12 @@ func (c *CLI) Errorf(format string, args ...interface{}) {
 	fmt.Fprintf(c.stderr, format, args...)
 }
 
+// errorln writes the message translated by the
+// configured catalog and a newline to stderr.
+func (c *CLI) errorln(format string, args ...interface{}) {
+	c.Errorf("%s\n", c.catalog.sprintf(format, args...))
+}
+
 // Scan reads one line of input on the configured stdin reader.
 func (c *CLI) Scan() string {
 	scanner := bufio.NewScanner(c.stdin)
@@ -248,16 +470,39 @@ func (c *CLI) Prompt(format string, args ...interface{}) string {
 // defaultHelpHandler is the default handler for the help command.
 func (c *CLI) defaultHelpHandler(args []string) error {
 	if len(args) == 0 {
-		return c.Usage(c.stdout, c.scope)
+		return c.page(func(w io.Writer) error {
+			return c.Usage(w, c.scope)
+		})
+	}
+	name := strings.Join(args, "/")
+	if len(args) > 1 && c.lookup(name) == nil {
+		return c.helpTopicNotFound(args)
 	}
-	if len(args) != 1 {
-		c.Errorf("Too many arguments given.\n")
-		c.Errorf("Run '%s help' for usage information.\n", c.name)
-		c.Errorf("Run '%s help [command]' for more information about a command.\n", c.name)
-		return ErrExitFailure
+	return c.page(func(w io.Writer) error {
+		return c.Usage(w, name)
+	})
+}
+
+// helpTopicNotFound reports the first word of a help topic that is not
+// a subcommand, with suggestions. Words after a command that has no
+// subcommands are reported as too many arguments.
+func (c *CLI) helpTopicNotFound(args []string) error {
+	var parent *Command
+	commands := c.commands
+	for _, name := range args {
+		if len(commands) == 0 {
+			break
+		}
+		cmd, ok := commands[name]
+		if !ok {
+			return c.commandNotFound(parent, name)
+		}
+		parent, commands = cmd, cmd.commands
 	}
-	name := args[0]
-	return c.Usage(c.stdout, name)
+	c.errorln("Too many arguments given.")
+	c.errorln("Run '%s help' for usage information.", c.name)
+	c.errorln("Run '%s help [command]' for more information about a command.", c.name)
+	return ErrExitFailure
 }
 
 // defaultDefaultHandler is the default handler for naked commands.
@@ -265,8 +510,15 @@ func (c *CLI) defaultDefaultHandler(args []string) error {
 	return ErrUsage
 }
 
-// defaultResolver is the default error resolver.
+// defaultResolver is the default error resolver. The message of
+// a wrapped localized error is translated in place.
 func (c *CLI) defaultResolver(err error) {
+	var l localized
+	if errors.As(err, &l) {
+		msg := strings.Replace(err.Error(), l.Error(), l.message(c.catalog), 1)
+		c.Errorf("%s\n", msg)
+		return
+	}
 	c.Errorf("%v\n", err)
 }
 
@@ -275,82 +527,3 @@ func (c *CLI) versionHandler(args []string) error {
 	c.Printf("%s\n", c.version)
 	return nil
 }
-
-// Parse parses flag definitions from the argument list. Flag parsing stops
-// at the first non-flag argument, including single or double hyphens followed
-// by whitespace or end of input.
-func Parse(args []string, flags []*Flag) ([]string, error) {
-	m := make(map[string]*Flag)
-	for _, f := range flags {
-		m[f.name] = f
-		if f.alias != "" {
-			m[f.alias] = f
-		}
-		value, ok := os.LookupEnv(f.envKey)
-		if ok {
-			f.Set(value)
-		}
-	}
-	key := ""
-	for arg := ""; len(args) > 0; {
-		arg, args = args[0], args[1:]
-		if arg == "-" || arg == "--" {
-			args = append([]string{arg}, args...)
-			break
-		}
-		if key != "" {
-			f, ok := m[key]
-			if !ok {
-				return nil, ErrUndefinedFlag(key)
-			}
-			if !f.kind.HasArg() {
-				key = ""
-				args = append([]string{arg}, args...)
-				f.Set("true")
-				continue
-			}
-			if arg[0] == '-' {
-				return nil, ErrRequiresArg(key)
-			}
-			key = ""
-			f.Set(arg)
-			continue
-		}
-		if arg == "" || arg[0] != '-' {
-			args = append([]string{arg}, args...)
-			break
-		}
-		if arg[1] == '-' {
-			arg = arg[2:]
-		} else {
-			arg = arg[1:]
-		}
-		if !unicode.IsLetter(rune(arg[0])) {
-			return nil, ErrFlagSyntax(arg)
-		}
-		i := strings.Index(arg, "=")
-		if i == -1 {
-			key = arg
-		} else {
-			key = arg[:i]
-			f, ok := m[key]
-			if !ok {
-				return nil, ErrUndefinedFlag(key)
-			}
-			key = ""
-			f.Set(arg[i+1:])
-		}
-	}
-	if key != "" {
-		f, ok := m[key]
-		if ok {
-			if f.kind.HasArg() {
-				return nil, ErrRequiresArg(key)
-			}
-			f.Set("true")
-		} else {
-			return nil, ErrUndefinedFlag(key)
-		}
-	}
-	return args, nil
-}
@@ -2,11 +2,17 @@ package cli
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"io"
+	"os"
+	"os/signal"
 	"reflect"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 type testCLI struct {
@@ -167,6 +173,43 @@ func TestParseEnv(t *testing.T) {
 	}
 }
 
+func testEnviron(env map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+}
+
+func TestParseEnvLookup(t *testing.T) {
+	t.Parallel()
+	c := &testCLI{}
+	lookup := testEnviron(map[string]string{"TEST_PARSE_ENV_LOOKUP": "env"})
+	flags := []*Flag{NewFlag("gs1", &c.gs1, EnvironmentKey("TEST_PARSE_ENV_LOOKUP"))}
+	_, err := ParseEnv(nil, flags, lookup)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.gs1 != "env" {
+		t.Fatalf("gs1\nhave '%s'\nwant '%s'", c.gs1, "env")
+	}
+}
+
+func TestRunEnviron(t *testing.T) {
+	t.Parallel()
+	var name string
+	env := map[string]string{"APPNAME_NAME": "env"}
+	flags := []*Flag{NewFlag("name", &name)}
+	app := New("appname", newTestUsage(t), flags, Environ(testEnviron(env)))
+	app.Add("test", testCommand, nil)
+	err := app.Run([]string{"appname", "test"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if name != "env" {
+		t.Fatalf("name\nhave '%s'\nwant '%s'", name, "env")
+	}
+}
+
 func TestAddNilHandler(t *testing.T) {
 	defer func() {
 		perr := recover()
@@ -254,3 +297,274 @@ func TestRunCommandErrUsage(t *testing.T) {
 		t.Fatalf("should return test command usage docs\nhave '%s'\nwant '%s'", have, want)
 	}
 }
+
+func TestRunSubcommand(t *testing.T) {
+	var have []string
+	c := &testCLI{}
+	app := New("appname", newTestUsage(t), nil, Stderr(io.Discard))
+	test := app.Add("test", testCommandErrUsage, []*Flag{NewFlag("gs1", &c.gs1)})
+	sub := test.Add("sub", testCommandErrUsage, []*Flag{NewFlag("gb1", &c.gb1, Bool())}, Alias("s"))
+	sub.Add("leaf", func(args []string) error {
+		have = args
+		return nil
+	}, []*Flag{NewFlag("gs2", &c.gs2)})
+	err := app.Run([]string{"appname", "test", "-gs1", "a", "s", "-gb1", "leaf", "-gs2", "b", "arg"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := &testCLI{gs1: "a", gs2: "b", gb1: true}
+	if !reflect.DeepEqual(c, want) {
+		t.Fatalf("flags\nhave %v\nwant %v", c, want)
+	}
+	if !reflect.DeepEqual(have, []string{"arg"}) {
+		t.Fatalf("args\nhave %v\nwant %v", have, []string{"arg"})
+	}
+}
+
+func TestRunSubcommandErrUsage(t *testing.T) {
+	var buf bytes.Buffer
+	app := New("appname", newTestUsage(t), nil, Stderr(&buf))
+	test := app.Add("test", testCommand, nil)
+	test.Add("sub", testCommandErrUsage, nil)
+	err := app.Run([]string{"appname", "test", "sub"})
+	if err != ErrExitFailure {
+		t.Fatalf("Run error\nhave %v\nwant %v", err, ErrExitFailure)
+	}
+	have := buf.String()
+	want := "test/sub.md\n"
+	if have != want {
+		t.Fatalf("should return subcommand usage docs\nhave '%s'\nwant '%s'", have, want)
+	}
+}
+
+func TestRunSubcommandNotFound(t *testing.T) {
+	var buf bytes.Buffer
+	app := New("appname", newTestUsage(t), nil, Stderr(&buf))
+	test := app.Add("test", testCommand, nil)
+	test.Add("sub", testCommand, nil)
+	err := app.Run([]string{"appname", "test", "sbu"})
+	if err != ErrExitFailure {
+		t.Fatalf("Run error\nhave %v\nwant %v", err, ErrExitFailure)
+	}
+	have := buf.String()
+	for _, want := range []string{"Unknown command 'sbu'.", "'appname help test'", "    sub\n"} {
+		if !strings.Contains(have, want) {
+			t.Fatalf("should suggest subcommands\nhave '%s'\nwant '%s'", have, want)
+		}
+	}
+}
+
+func TestAddDuplicateSubcommand(t *testing.T) {
+	defer func() {
+		perr := recover()
+		if perr == nil {
+			t.Fatalf("duplicate subcommand should panic")
+		}
+	}()
+	app := New("appname", newTestUsage(t), nil)
+	test := app.Add("test", testCommand, nil)
+	test.Add("sub", testCommand, nil)
+	test.Add("sub", testCommand, nil)
+}
+
+type testContextKey struct{}
+
+func TestRunContext(t *testing.T) {
+	var have interface{}
+	app := New("appname", newTestUsage(t), nil, Stderr(io.Discard))
+	app.UseContext(func(next ContextHandler) ContextHandler {
+		return func(ctx context.Context, args []string) error {
+			ctx = context.WithValue(ctx, testContextKey{}, "middleware")
+			return next(ctx, args)
+		}
+	})
+	app.Use(func(next Handler) Handler {
+		return func(args []string) error {
+			return next(append(args, "wrapped"))
+		}
+	})
+	app.AddContext("test", func(ctx context.Context, args []string) error {
+		have = ctx.Value(testContextKey{})
+		if !reflect.DeepEqual(args, []string{"wrapped"}) {
+			t.Fatalf("args\nhave %v\nwant %v", args, []string{"wrapped"})
+		}
+		return ctx.Err()
+	}, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	err := app.RunContext(ctx, []string{"appname", "test"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if have != "middleware" {
+		t.Fatalf("context value\nhave %v\nwant %v", have, "middleware")
+	}
+	cancel()
+	err = app.RunContext(ctx, []string{"appname", "test"})
+	if err != context.Canceled {
+		t.Fatalf("Run error\nhave %v\nwant %v", err, context.Canceled)
+	}
+}
+
+func TestRunContextSignal(t *testing.T) {
+	p, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	app := New("appname", newTestUsage(t), nil, Stderr(io.Discard))
+	app.AddContext("test", func(ctx context.Context, args []string) error {
+		err := p.Signal(os.Interrupt)
+		if err != nil {
+			t.Skipf("cannot signal process: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(5 * time.Second):
+			return nil
+		}
+	}, nil)
+	err = app.Run([]string{"appname", "test"})
+	if err != context.Canceled {
+		t.Fatalf("Run error\nhave %v\nwant %v", err, context.Canceled)
+	}
+}
+
+func TestRunSignalPlainHandler(t *testing.T) {
+	p, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	exited := make(chan int, 1)
+	exit := ExitFunc(func(code int) { exited <- code })
+	app := New("appname", newTestUsage(t), nil, exit, Stderr(io.Discard))
+	app.Add("test", func(args []string) error {
+		ch := make(chan os.Signal, 1)
+		signal.Notify(ch, os.Interrupt)
+		defer signal.Stop(ch)
+		err := p.Signal(os.Interrupt)
+		if err != nil {
+			t.Skipf("cannot signal process: %v", err)
+		}
+		select {
+		case <-ch:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("handler should receive the signal")
+		}
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	}, nil)
+	err = app.Run([]string{"appname", "test"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	select {
+	case code := <-exited:
+		t.Fatalf("handler should handle its own signals\nexit code %d", code)
+	default:
+	}
+}
+
+func TestRunRequiredFlags(t *testing.T) {
+	var have error
+	c := &testCLI{}
+	flags := []*Flag{NewFlag("gs1", &c.gs1, Required())}
+	app := New("appname", newTestUsage(t), flags, Resolver(func(err error) { have = err }))
+	app.Add("test", testCommand, []*Flag{
+		NewFlag("gs2", &c.gs2, Required()),
+		NewFlag("gb1", &c.gb1, Bool(), Required(), DefaultValue("true")),
+	})
+	err := app.Run([]string{"appname", "test"})
+	want := ErrMissingFlags{"gs1", "gs2"}
+	if !reflect.DeepEqual(err, want) {
+		t.Fatalf("Run error\nhave %v\nwant %v", err, want)
+	}
+	if !reflect.DeepEqual(have, want) {
+		t.Fatalf("should resolve error\nhave %v\nwant %v", have, want)
+	}
+	app = New("appname", newTestUsage(t), flags, Stdout(io.Discard))
+	err = app.Run([]string{"appname", "help"})
+	if err != nil {
+		t.Fatalf("help should not require flags: %v", err)
+	}
+}
+
+func TestRunRequiredFlagsDefault(t *testing.T) {
+	var name string
+	called := false
+	flags := []*Flag{NewFlag("name", &name, Required())}
+	app := New("appname", newTestUsage(t), flags, Default(func(args []string) error {
+		called = true
+		return nil
+	}), Resolver(func(error) {}))
+	err := app.Run([]string{"appname"})
+	want := ErrMissingFlags{"name"}
+	if !reflect.DeepEqual(err, want) {
+		t.Fatalf("Run error\nhave %v\nwant %v", err, want)
+	}
+	if called {
+		t.Fatal("default handler should not be called")
+	}
+	err = app.Run([]string{"appname", "--name", "x"})
+	if err != nil || !called {
+		t.Fatalf("default handler should be called: %v", err)
+	}
+}
+
+func TestRunRepeated(t *testing.T) {
+	c := &testCLI{gs1: "initial"}
+	var tags []string
+	flags := []*Flag{
+		NewFlag("gs1", &c.gs1),
+		NewFlag("gs2", &c.gs2, DefaultValue("default")),
+	}
+	app := New("appname", newTestUsage(t), flags)
+	app.Add("test", testCommand, []*Flag{NewFlag("tag", &tags)})
+	tests := []struct {
+		args []string
+		gs1  string
+		gs2  string
+		tags []string
+	}{
+		{[]string{"appname", "--gs1", "a", "--gs2", "b", "test", "--tag", "x"}, "a", "b", []string{"x"}},
+		{[]string{"appname", "test", "--tag", "y"}, "initial", "default", []string{"y"}},
+		{[]string{"appname", "test"}, "initial", "default", nil},
+	}
+	for i, tt := range tests {
+		err := app.Run(tt.args)
+		if err != nil {
+			t.Fatalf("%d: unexpected error: %v", i, err)
+		}
+		if c.gs1 != tt.gs1 || c.gs2 != tt.gs2 || !reflect.DeepEqual(tags, tt.tags) {
+			t.Fatalf("%d: values\nhave %q %q %v\nwant %q %q %v", i, c.gs1, c.gs2, tags, tt.gs1, tt.gs2, tt.tags)
+		}
+		if i > 0 && app.flagsMap["gs1"].IsSet() {
+			t.Fatalf("%d: gs1 should not be set", i)
+		}
+	}
+}
+
+func TestRunConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		var name string
+		want := strconv.Itoa(i)
+		app := New("appname", newTestUsage(t), []*Flag{NewFlag("name", &name)})
+		app.Add("test", testCommand, nil)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				err := app.Run([]string{"appname", "--name", want, "test"})
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+					return
+				}
+				if name != want {
+					t.Errorf("name\nhave %q\nwant %q", name, want)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -1,24 +1,50 @@
 package cli
 
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
 // Command represents an application command.
 type Command struct {
-	name       string
-	alias      string
-	proxy      bool
-	flags      []*Flag
-	handler    Handler
-	middleware []func(Handler) Handler
+	name         string
+	path         string
+	alias        string
+	description  string
+	proxy        bool
+	builtin      bool
+	interspersed bool
+	context      bool
+	args         *argSpec
+	flags        []*Flag
+	groups       []flagGroup
+	handler      ContextHandler
+	commands     map[string]*Command
+	middleware   []func(ContextHandler) ContextHandler
 }
 
 // Handler represents a command handler.
 type Handler func(args []string) error
 
+// ContextHandler represents a command handler that receives the
+// run context. The context is cancelled when the application
+// receives an interrupt or termination signal.
+type ContextHandler func(ctx context.Context, args []string) error
+
 // NewCommand returns a new command.
 func NewCommand(name string, handler Handler, flags []*Flag, opts ...CommandOption) *Command {
+	return newCommand(name, withContext(handler), flags, opts...)
+}
+
+// newCommand returns a new command with a context aware handler.
+func newCommand(name string, handler ContextHandler, flags []*Flag, opts ...CommandOption) *Command {
 	c := &Command{
 		name:       name,
+		path:       name,
 		flags:      flags,
-		middleware: make([]func(Handler) Handler, 0),
+		commands:   make(map[string]*Command),
+		middleware: make([]func(ContextHandler) ContextHandler, 0),
 	}
 	for _, option := range opts {
 		option(c)
@@ -27,14 +53,93 @@ func NewCommand(name string, handler Handler, flags []*Flag, opts ...CommandOpti
 	return c
 }
 
+// Add adds a new subcommand. Subcommands inherit the
+// middleware stack of the parent command.
+func (c *Command) Add(name string, handler Handler, flags []*Flag, opts ...CommandOption) *Command {
+	cmd := c.AddContext(name, withContext(handler), flags, opts...)
+	cmd.context = false
+	return cmd
+}
+
+// AddContext adds a new subcommand with a context aware handler.
+func (c *Command) AddContext(name string, handler ContextHandler, flags []*Flag, opts ...CommandOption) *Command {
+	opt := WithContextMiddleware(c.middleware...)
+	opts = append([]CommandOption{opt}, opts...)
+	cmd := addCommand(c.commands, c.path+"/", name, handler, flags, opts)
+	cmd.context = true
+	return cmd
+}
+
+// dispatch validates args and calls the command handler.
+func (c *Command) dispatch(ctx context.Context, args []string) error {
+	err := c.args.validate(args)
+	if err != nil {
+		return err
+	}
+	return c.handler(ctx, args)
+}
+
 // build wraps h with the configured middleware.
-func (c *Command) build(h Handler) {
+func (c *Command) build(h ContextHandler) {
 	c.handler = h
 	for i := len(c.middleware) - 1; i >= 0; i-- {
 		c.handler = c.middleware[i](c.handler)
 	}
 }
 
+// addCommand adds a new command to commands. The command
+// path is the command name prefixed with the parent path.
+func addCommand(commands map[string]*Command, prefix, name string, handler ContextHandler, flags []*Flag, opts []CommandOption) *Command {
+	name = strings.ToLower(name)
+	if handler == nil {
+		panic(fmt.Errorf("cli: command '%s' has nil handler", prefix+name))
+	}
+	_, ok := commands[name]
+	if ok {
+		panic(fmt.Errorf("cli: duplicate command '%s'", prefix+name))
+	}
+	cmd := newCommand(name, handler, flags, opts...)
+	cmd.path = prefix + name
+	commands[name] = cmd
+	if cmd.alias != "" {
+		dup, ok := commands[cmd.alias]
+		if ok {
+			panic(fmt.Errorf("cli: duplicate command alias '%s' for '%s'", cmd.alias, dup.path))
+		}
+		commands[cmd.alias] = cmd
+	}
+	return cmd
+}
+
+// withContext adapts h to a context aware handler.
+// The context is discarded. A nil handler returns nil.
+func withContext(h Handler) ContextHandler {
+	if h == nil {
+		return nil
+	}
+	return func(ctx context.Context, args []string) error {
+		return h(args)
+	}
+}
+
+// contextMiddleware adapts middleware that is unaware of the
+// context so that it may wrap a context aware handler.
+func contextMiddleware(middleware ...func(Handler) Handler) []func(ContextHandler) ContextHandler {
+	rv := make([]func(ContextHandler) ContextHandler, len(middleware))
+	for i, m := range middleware {
+		m := m
+		rv[i] = func(next ContextHandler) ContextHandler {
+			return func(ctx context.Context, args []string) error {
+				h := m(func(args []string) error {
+					return next(ctx, args)
+				})
+				return h(args)
+			}
+		}
+	}
+	return rv
+}
+
 // CommandOption represents a functional option for command configuration.
 type CommandOption func(*Command)
 
@@ -45,6 +150,13 @@ func Alias(name string) CommandOption {
 	}
 }
 
+// Description sets the command description for generated help output.
+func Description(text string) CommandOption {
+	return func(c *Command) {
+		c.description = text
+	}
+}
+
 // Proxy instructs the dispatcher to proxy the unparsed
 // arguments to the command itself for further processing.
 func Proxy() CommandOption {
@@ -53,8 +165,24 @@ func Proxy() CommandOption {
 	}
 }
 
+// Interspersed instructs the flag parser to accept command flags
+// anywhere after the command name rather than stopping at the first
+// non-flag argument. A double hyphen still ends flag processing. This
+// option has no effect on commands with subcommands or proxy commands.
+func Interspersed() CommandOption {
+	return func(c *Command) {
+		c.interspersed = true
+	}
+}
+
 // WithMiddleware appends middleware to the middleware stack.
 func WithMiddleware(middleware ...func(Handler) Handler) CommandOption {
+	return WithContextMiddleware(contextMiddleware(middleware...)...)
+}
+
+// WithContextMiddleware appends context aware
+// middleware to the middleware stack.
+func WithContextMiddleware(middleware ...func(ContextHandler) ContextHandler) CommandOption {
 	return func(c *Command) {
 		c.middleware = append(c.middleware, middleware...)
 	}
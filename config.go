@@ -0,0 +1,299 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// configFlagName is the name of the flag that overrides
+// the configuration file path.
+const configFlagName = "config"
+
+// config represents a parsed configuration file. Keys are flag
+// names or command names. Command names map to nested sections.
+type config map[string]interface{}
+
+// readConfig reads the configuration file. The path is taken from the
+// parsed config flag, set on the command line or by its environment
+// variable, or the first file found in the XDG configuration directories,
+// in that order. The path is returned with the config. A nil config is
+// returned if no configuration file exists.
+func (c *CLI) readConfig() (config, string, error) {
+	if c.configName == "" {
+		return nil, "", nil
+	}
+	path := c.configPath
+	if path == "" {
+		path = c.findConfig()
+		if path == "" {
+			return nil, "", nil
+		}
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+	var cfg config
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		cfg, err = parseJSONConfig(b)
+	} else {
+		cfg, err = parseINIConfig(b)
+	}
+	if err != nil {
+		return nil, "", ErrInvalidConfig{Path: path, Err: err}
+	}
+	return cfg, path, nil
+}
+
+// findConfig returns the path of the first configuration
+// file found in the XDG configuration directories.
+func (c *CLI) findConfig() string {
+	var dirs []string
+	home := c.getenv("XDG_CONFIG_HOME")
+	if home == "" {
+		dir := c.getenv("HOME")
+		if dir != "" {
+			home = filepath.Join(dir, ".config")
+		}
+	}
+	if home != "" {
+		dirs = append(dirs, home)
+	}
+	system := c.getenv("XDG_CONFIG_DIRS")
+	if system == "" {
+		system = "/etc/xdg"
+	}
+	dirs = append(dirs, filepath.SplitList(system)...)
+	for _, dir := range dirs {
+		path := filepath.Join(dir, c.name, c.configName)
+		_, err := os.Stat(path)
+		if err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// apply loads values from the section for the slash separated
+// command path into flags. Keys match flag names after both are
+// normalized as environment variable keys are. The file is the
+// configuration file path recorded as the flag value source. Flags
+// already set by environment variables or on the command line are
+// left unchanged as they take precedence.
+func (cfg config) apply(file, path string, flags []*Flag) error {
+	if cfg == nil {
+		return nil
+	}
+	section := cfg
+	if path != "" {
+		for _, name := range strings.Split(path, "/") {
+			v, ok := section.lookup(name)
+			if !ok {
+				return nil
+			}
+			section, ok = v.(map[string]interface{})
+			if !ok {
+				return nil
+			}
+		}
+	}
+	for _, f := range flags {
+		if f.source.Kind > SourceConfig {
+			continue
+		}
+		v, ok := section.lookup(f.name)
+		if !ok {
+			continue
+		}
+		values, ok := configValues(v)
+		if !ok {
+			err := fmt.Errorf("unsupported value type %T", v)
+			return ErrInvalidFlagValue{Flag: f.name, Value: fmt.Sprint(v), Err: err}
+		}
+		var err error
+		if len(values) == 1 {
+			err = f.load(values[0])
+		} else {
+			err = f.loadValues(values)
+		}
+		if err != nil {
+			return err
+		}
+		f.count++
+		f.source = Source{Kind: SourceConfig, Name: file, Value: f.value}
+	}
+	return nil
+}
+
+// lookup returns the value for the normalized key.
+func (cfg config) lookup(key string) (interface{}, bool) {
+	key = normalize(key)
+	for k, v := range cfg {
+		if normalize(k) == key {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// normalize returns key in lower case with separators
+// replaced as they are for environment variable keys.
+func normalize(key string) string {
+	return strings.ToLower(mapper.Replace(key))
+}
+
+// configValues returns the string values of a configuration value.
+// Arrays return one value per element and objects return sorted
+// key=value pairs for map flags.
+func configValues(v interface{}) ([]string, bool) {
+	switch v := v.(type) {
+	case string:
+		return []string{v}, true
+	case bool:
+		return []string{strconv.FormatBool(v)}, true
+	case json.Number:
+		return []string{v.String()}, true
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, e := range v {
+			s, ok := configValues(e)
+			if !ok || len(s) != 1 {
+				return nil, false
+			}
+			values = append(values, s[0])
+		}
+		return values, true
+	case map[string]interface{}:
+		values := make([]string, 0, len(v))
+		for k, e := range v {
+			s, ok := configValues(e)
+			if !ok || len(s) != 1 {
+				return nil, false
+			}
+			values = append(values, k+"="+s[0])
+		}
+		sort.Strings(values)
+		return values, true
+	}
+	return nil, false
+}
+
+// parseJSONConfig parses a JSON configuration file. The
+// file must contain a single object.
+func parseJSONConfig(b []byte) (config, error) {
+	var cfg map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	err := dec.Decode(&cfg)
+	if err != nil {
+		return nil, err
+	}
+	return config(cfg), nil
+}
+
+// parseINIConfig parses an INI style configuration file of key=value
+// lines. Section headers such as [deploy] or [cluster.node] contain
+// the values for commands. Lines starting with # or ; are comments.
+// Values may be quoted and repeated keys collect into arrays, as do
+// values wrapped in square brackets.
+func parseINIConfig(b []byte) (config, error) {
+	cfg := make(config)
+	section := cfg
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if line[0] == '[' {
+			if line[len(line)-1] != ']' {
+				return nil, fmt.Errorf("line %d: unterminated section", n)
+			}
+			section = cfg
+			for _, name := range strings.FieldsFunc(line[1:len(line)-1], isSectionSep) {
+				next, ok := section[name].(map[string]interface{})
+				if !ok {
+					next = make(map[string]interface{})
+					section[name] = next
+				}
+				section = next
+			}
+			continue
+		}
+		i := strings.Index(line, "=")
+		if i < 1 {
+			return nil, fmt.Errorf("line %d: expected key=value", n)
+		}
+		key := strings.TrimSpace(line[:i])
+		value, err := parseINIValue(strings.TrimSpace(line[i+1:]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", n, err)
+		}
+		prev, ok := section[key]
+		if !ok {
+			section[key] = value
+			continue
+		}
+		values, ok := prev.([]interface{})
+		if !ok {
+			values = []interface{}{prev}
+		}
+		section[key] = append(values, value)
+	}
+	err := scanner.Err()
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// parseINIValue returns the unquoted value or an array of
+// unquoted values if the value is wrapped in square brackets.
+func parseINIValue(value string) (interface{}, error) {
+	if len(value) < 2 || value[0] != '[' || value[len(value)-1] != ']' {
+		return unquote(value)
+	}
+	var values []interface{}
+	for _, e := range strings.Split(value[1:len(value)-1], ",") {
+		e = strings.TrimSpace(e)
+		if e == "" {
+			continue
+		}
+		v, err := unquote(e)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// unquote returns value without surrounding single or double quotes.
+func unquote(value string) (string, error) {
+	if len(value) < 2 {
+		return value, nil
+	}
+	switch value[0] {
+	case '"':
+		return strconv.Unquote(value)
+	case '\'':
+		if value[len(value)-1] != '\'' {
+			return "", errors.New("unterminated string")
+		}
+		return value[1 : len(value)-1], nil
+	}
+	return value, nil
+}
+
+// isSectionSep reports whether r separates command names in a section.
+func isSectionSep(r rune) bool {
+	return r == '.' || r == '/' || r == ' '
+}
@@ -0,0 +1,182 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+type testConfigFile struct {
+	host    string
+	port    int
+	verbose bool
+	tags    []string
+	labels  map[string]string
+	force   bool
+}
+
+func newTestConfigApp(t *testing.T, c *testConfigFile, opts ...Option) *CLI {
+	t.Helper()
+	flags := []*Flag{
+		NewFlag("host", &c.host, DefaultValue("localhost")),
+		NewFlag("port", &c.port, DefaultValue("80")),
+		NewFlag("verbose", &c.verbose),
+	}
+	opts = append([]Option{Prefix("TEST_CONFIG")}, opts...)
+	app := New("appname", newTestUsage(t), flags, opts...)
+	app.Add("deploy", testCommand, []*Flag{
+		NewFlag("dry-run", &c.force),
+		NewFlag("tag", &c.tags),
+		NewFlag("label", &c.labels),
+	})
+	return app
+}
+
+func writeTestConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	err := os.WriteFile(path, []byte(content), 0644)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return path
+}
+
+func TestConfigJSON(t *testing.T) {
+	path := writeTestConfig(t, "config.json", `{
+		"host": "example.com",
+		"port": 8080,
+		"Verbose": true,
+		"deploy": {"dry_run": true, "tag": ["a", "b"], "label": {"k": "v"}}
+	}`)
+	c := &testConfigFile{}
+	app := newTestConfigApp(t, c, ConfigFile("config.json"))
+	err := app.Run([]string{"appname", "--config", path, "deploy"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := &testConfigFile{
+		host:    "example.com",
+		port:    8080,
+		verbose: true,
+		force:   true,
+		tags:    []string{"a", "b"},
+		labels:  map[string]string{"k": "v"},
+	}
+	if !reflect.DeepEqual(c, want) {
+		t.Fatalf("config\nhave %+v\nwant %+v", c, want)
+	}
+}
+
+func TestConfigINI(t *testing.T) {
+	path := writeTestConfig(t, "config", `
+# comment
+host = "example.com"
+port=8080
+
+[deploy]
+dry-run = true
+tag = a
+tag = b
+label = ["k=v", 'x=y']
+`)
+	c := &testConfigFile{}
+	app := newTestConfigApp(t, c, ConfigFile("config"))
+	err := app.Run([]string{"appname", "--config=" + path, "deploy"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := &testConfigFile{
+		host:   "example.com",
+		port:   8080,
+		force:  true,
+		tags:   []string{"a", "b"},
+		labels: map[string]string{"k": "v", "x": "y"},
+	}
+	if !reflect.DeepEqual(c, want) {
+		t.Fatalf("config\nhave %+v\nwant %+v", c, want)
+	}
+}
+
+func TestConfigPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	err := os.MkdirAll(filepath.Join(dir, "appname"), 0755)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	content := "host = config\nport = 1\n[deploy]\ntag = config\n"
+	err = os.WriteFile(filepath.Join(dir, "appname", "config"), []byte(content), 0644)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("TEST_CONFIG_PORT", "2")
+	t.Setenv("TEST_CONFIG_TAG", "env")
+	c := &testConfigFile{}
+	app := newTestConfigApp(t, c, ConfigFile("config"))
+	err = app.Run([]string{"appname", "deploy", "--tag", "flag"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := &testConfigFile{
+		host: "config",
+		port: 2,
+		tags: []string{"flag"},
+	}
+	if !reflect.DeepEqual(c, want) {
+		t.Fatalf("precedence\nhave %+v\nwant %+v", c, want)
+	}
+}
+
+func TestConfigInvalid(t *testing.T) {
+	path := writeTestConfig(t, "config", "[deploy\n")
+	c := &testConfigFile{}
+	app := newTestConfigApp(t, c, ConfigFile("config"), Resolver(func(error) {}))
+	err := app.Run([]string{"appname", "--config", path, "deploy"})
+	_, ok := err.(ErrInvalidConfig)
+	if !ok {
+		t.Fatalf("error\nhave %v\nwant %T", err, ErrInvalidConfig{})
+	}
+}
+
+func TestConfigRootFlag(t *testing.T) {
+	var tests = []struct {
+		args []string
+		want error
+	}{
+		{[]string{"appname", "exec", "tool", "--config", "x"}, nil},
+		{[]string{"appname", "deploy", "--config", "x"}, ErrUndefinedFlag("config")},
+	}
+	for _, tt := range tests {
+		c := &testConfigFile{}
+		app := newTestConfigApp(t, c, ConfigFile("config"), Resolver(func(error) {}))
+		app.Add("exec", testCommand, nil, Proxy())
+		err := app.Run(tt.args)
+		if err != tt.want {
+			t.Fatalf("%v\nhave %v\nwant %v", tt.args, err, tt.want)
+		}
+	}
+}
+
+func TestConfigPrecedenceGroups(t *testing.T) {
+	path := writeTestConfig(t, "config", "[load]\nfile = a.txt\n")
+	var file string
+	var stdin bool
+	f1 := NewFlag("file", &file)
+	f2 := NewFlag("stdin", &stdin, Bool())
+	app := New("appname", newTestUsage(t), nil, ConfigFile("config"), Resolver(func(error) {}))
+	app.Add("load", testCommand, []*Flag{f1, f2}, MutuallyExclusive(f1, f2))
+	err := app.Run([]string{"appname", "--config", path, "load", "--stdin"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if file != "a.txt" || !stdin {
+		t.Fatalf("flags\nhave file=%q stdin=%t\nwant file=%q stdin=%t", file, stdin, "a.txt", true)
+	}
+	err = app.Run([]string{"appname", "--config", path, "load", "--file", "b.txt", "--stdin"})
+	want := ErrMutuallyExclusive{"file", "stdin"}
+	if !reflect.DeepEqual(err, want) {
+		t.Fatalf("error\nhave %v\nwant %v", err, want)
+	}
+}
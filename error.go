@@ -19,7 +19,12 @@ type ErrFlagSyntax string
 
 // Error implements the error interface.
 func (e ErrFlagSyntax) Error() string {
-	return fmt.Sprintf("Flag '%s' is syntactically incorrect.", string(e))
+	return e.message(nil)
+}
+
+// message returns the error message translated by cat.
+func (e ErrFlagSyntax) message(cat Catalog) string {
+	return cat.sprintf("Flag '%s' is syntactically incorrect.", string(e))
 }
 
 // ErrUndefinedFlag represents an error for when an undefined flag is parsed.
@@ -27,7 +32,12 @@ type ErrUndefinedFlag string
 
 // Error implements the error interface.
 func (e ErrUndefinedFlag) Error() string {
-	return fmt.Sprintf("Flag '%s' is undefined.", string(e))
+	return e.message(nil)
+}
+
+// message returns the error message translated by cat.
+func (e ErrUndefinedFlag) message(cat Catalog) string {
+	return cat.sprintf("Flag '%s' is undefined.", string(e))
 }
 
 // ErrRequiresArg represents an error for when an undefined flag is parsed.
@@ -35,5 +45,139 @@ type ErrRequiresArg string
 
 // Error implements the error interface.
 func (e ErrRequiresArg) Error() string {
-	return fmt.Sprintf("Flag '%s' requires an argument.", string(e))
+	return e.message(nil)
+}
+
+// message returns the error message translated by cat.
+func (e ErrRequiresArg) message(cat Catalog) string {
+	return cat.sprintf("Flag '%s' requires an argument.", string(e))
+}
+
+// ErrMissingArg represents an error for when a
+// required positional argument is not given.
+type ErrMissingArg string
+
+// Error implements the error interface.
+func (e ErrMissingArg) Error() string {
+	return e.message(nil)
+}
+
+// message returns the error message translated by cat.
+func (e ErrMissingArg) message(cat Catalog) string {
+	return cat.sprintf("Argument '%s' is required.", string(e))
+}
+
+// ErrUnexpectedArg represents an error for when more positional
+// arguments are given than allowed. The value is the first
+// unexpected argument.
+type ErrUnexpectedArg string
+
+// Error implements the error interface.
+func (e ErrUnexpectedArg) Error() string {
+	return e.message(nil)
+}
+
+// message returns the error message translated by cat.
+func (e ErrUnexpectedArg) message(cat Catalog) string {
+	return cat.sprintf("Argument '%s' is unexpected.", string(e))
+}
+
+// ErrInvalidFlagValue represents an error for when
+// a flag value cannot be parsed by the flag kind.
+type ErrInvalidFlagValue struct {
+	Flag  string
+	Value string
+	Err   error
+}
+
+// Error implements the error interface.
+func (e ErrInvalidFlagValue) Error() string {
+	return e.message(nil)
+}
+
+// message returns the error message translated by cat.
+func (e ErrInvalidFlagValue) message(cat Catalog) string {
+	return cat.sprintf("Flag '%s' has invalid value '%s': %v.", e.Flag, e.Value, e.Err)
+}
+
+// Unwrap returns the underlying parse error.
+func (e ErrInvalidFlagValue) Unwrap() error {
+	return e.Err
+}
+
+// ErrMissingFlags represents an error for when required flags are not set.
+type ErrMissingFlags []string
+
+// Error implements the error interface.
+func (e ErrMissingFlags) Error() string {
+	return e.message(nil)
+}
+
+// message returns the error message translated by cat.
+func (e ErrMissingFlags) message(cat Catalog) string {
+	if len(e) == 1 {
+		return cat.sprintf("Flag '%s' is required.", e[0])
+	}
+	return cat.sprintf("Flags %s are required.", quoteList(cat, e))
+}
+
+// ErrMutuallyExclusive represents an error for when more than one
+// flag of a mutually exclusive group is set. The value lists the
+// flags that were set.
+type ErrMutuallyExclusive []string
+
+// Error implements the error interface.
+func (e ErrMutuallyExclusive) Error() string {
+	return e.message(nil)
+}
+
+// message returns the error message translated by cat.
+func (e ErrMutuallyExclusive) message(cat Catalog) string {
+	return cat.sprintf("Flags %s are mutually exclusive.", quoteList(cat, e))
+}
+
+// ErrRequiredTogether represents an error for when some but not all
+// flags of a group that must be used together are set. The value
+// lists every flag in the group.
+type ErrRequiredTogether []string
+
+// Error implements the error interface.
+func (e ErrRequiredTogether) Error() string {
+	return e.message(nil)
+}
+
+// message returns the error message translated by cat.
+func (e ErrRequiredTogether) message(cat Catalog) string {
+	return cat.sprintf("Flags %s must be used together.", quoteList(cat, e))
+}
+
+// quoteList quotes names and joins them as a list translated by cat.
+func quoteList(cat Catalog, names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = "'" + name + "'"
+	}
+	return joinList(cat, quoted)
+}
+
+// ErrInvalidConfig represents an error for when
+// the configuration file cannot be parsed.
+type ErrInvalidConfig struct {
+	Path string
+	Err  error
+}
+
+// Error implements the error interface.
+func (e ErrInvalidConfig) Error() string {
+	return e.message(nil)
+}
+
+// message returns the error message translated by cat.
+func (e ErrInvalidConfig) message(cat Catalog) string {
+	return cat.sprintf("Config file '%s' is invalid: %v.", e.Path, e.Err)
+}
+
+// Unwrap returns the underlying parse error.
+func (e ErrInvalidConfig) Unwrap() error {
+	return e.Err
 }
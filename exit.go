@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Exit status codes returned by ExitCode.
+const (
+	ExitSuccess   = 0
+	ExitFailure   = 1
+	ExitUsage     = 2
+	ExitInterrupt = 130
+)
+
+// errCommandNotFound represents the error returned after an unknown
+// command is reported. It is rewritten as ErrExitFailure by Run.
+var errCommandNotFound = fmt.Errorf("%w", ErrExitFailure)
+
+// ExitError represents an error that exits with a specific status.
+// An ExitError with a nil Err exits without output from the resolver.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+// Error implements the error interface.
+func (e ExitError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("exit status %d", e.Code)
+	}
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e ExitError) Unwrap() error {
+	return e.Err
+}
+
+// errorType is the reflection type of the error interface.
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// exitCode represents an exit status for the errors matched by match.
+type exitCode struct {
+	match func(err error) bool
+	code  int
+}
+
+// matchIs returns a function that reports whether an error
+// matches target as reported by errors.Is.
+func matchIs(target error) func(err error) bool {
+	return func(err error) bool {
+		return errors.Is(err, target)
+	}
+}
+
+// matchAs returns a function that reports whether an error matches
+// the type target points to as reported by errors.As. A new value is
+// allocated for each match so target itself is never assigned.
+func matchAs(target interface{}) func(err error) bool {
+	typ := reflect.TypeOf(target)
+	if typ == nil || typ.Kind() != reflect.Ptr || reflect.ValueOf(target).IsNil() {
+		panic("cli: exit code target must be a non-nil pointer")
+	}
+	elem := typ.Elem()
+	if elem.Kind() != reflect.Interface && !elem.Implements(errorType) {
+		panic(fmt.Errorf("cli: exit code target type %s does not implement error", elem))
+	}
+	return func(err error) bool {
+		return errors.As(err, reflect.New(elem).Interface())
+	}
+}
+
+// ExitCode returns the exit status for err. The code of an ExitError
+// is returned as is. Usage errors, including flag, argument and unknown
+// command errors, return ExitUsage. Cancellation by signal returns
+// ExitInterrupt. Any other non-nil error returns ExitFailure.
+//
+// Run reports usage errors as ErrExitFailure once usage information
+// has been written, so ExitCode returns ExitFailure for them. Use Main
+// to exit with the status of the original error, including any codes
+// registered with ExitCodeFor and ExitCodeAs.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitSuccess
+	}
+	var e ExitError
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	if errors.Is(err, context.Canceled) {
+		return ExitInterrupt
+	}
+	if isUsageError(err) {
+		return ExitUsage
+	}
+	return ExitFailure
+}
+
+// exitCode returns the exit status for err. Codes registered with
+// ExitCodeFor and ExitCodeAs take precedence over the defaults of
+// ExitCode, but not over the code of an ExitError.
+func (c *CLI) exitCode(err error) int {
+	var e ExitError
+	if err == nil || errors.As(err, &e) {
+		return ExitCode(err)
+	}
+	for _, m := range c.exitCodes {
+		if m.match(err) {
+			return m.code
+		}
+	}
+	return ExitCode(err)
+}
+
+// isUsageError returns true if err is or wraps a usage error.
+func isUsageError(err error) bool {
+	if errors.Is(err, ErrUsage) || errors.Is(err, errCommandNotFound) {
+		return true
+	}
+	for ; err != nil; err = errors.Unwrap(err) {
+		switch err.(type) {
+		case ErrFlagSyntax, ErrUndefinedFlag, ErrRequiresArg,
+			ErrMissingArg, ErrUnexpectedArg, ErrInvalidFlagValue,
+			ErrMissingFlags, ErrMutuallyExclusive, ErrRequiredTogether:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, ExitSuccess},
+		{errors.New("failure"), ExitFailure},
+		{ErrExitFailure, ExitFailure},
+		{ErrUsage, ExitUsage},
+		{fmt.Errorf("deploy: %w", ErrUsage), ExitUsage},
+		{ErrUndefinedFlag("x"), ExitUsage},
+		{ErrMissingArg("file"), ExitUsage},
+		{ErrMissingFlags{"x"}, ExitUsage},
+		{ErrInvalidFlagValue{Flag: "x", Value: "y", Err: errors.New("z")}, ExitUsage},
+		{errCommandNotFound, ExitUsage},
+		{context.Canceled, ExitInterrupt},
+		{ExitError{Code: 3}, 3},
+		{fmt.Errorf("wrapped: %w", ExitError{Code: 4, Err: ErrUsage}), 4},
+	}
+	for _, tt := range tests {
+		have := ExitCode(tt.err)
+		if have != tt.want {
+			t.Errorf("ExitCode(%v)\nhave %d\nwant %d", tt.err, have, tt.want)
+		}
+	}
+}
+
+func TestCLIMain(t *testing.T) {
+	args := os.Args
+	defer func() { os.Args = args }()
+	tests := []struct {
+		args []string
+		want int
+	}{
+		{[]string{"appname", "ok"}, ExitSuccess},
+		{[]string{"appname", "fail"}, ExitFailure},
+		{[]string{"appname", "test"}, ExitUsage},
+		{[]string{"appname", "code"}, 3},
+		{[]string{"appname", "--undefined", "ok"}, ExitUsage},
+		{[]string{"appname", "unknown"}, ExitUsage},
+	}
+	for _, tt := range tests {
+		have := -1
+		var resolved error
+		exit := ExitFunc(func(code int) { have = code })
+		resolver := Resolver(func(err error) { resolved = err })
+		app := New("appname", newTestUsage(t), nil, exit, resolver, Stderr(io.Discard))
+		app.Add("ok", testCommand, nil)
+		app.Add("fail", testCommandFailure, nil)
+		app.Add("test", testCommandErrUsage, nil)
+		app.Add("code", func(args []string) error {
+			return ExitError{Code: 3}
+		}, nil)
+		os.Args = tt.args
+		app.Main()
+		if have != tt.want {
+			t.Errorf("%v\nhave %d\nwant %d", tt.args, have, tt.want)
+		}
+		if tt.args[1] == "code" && resolved != nil {
+			t.Errorf("ExitError without error should not resolve: %v", resolved)
+		}
+	}
+}
+
+func TestExitCodeFor(t *testing.T) {
+	args := os.Args
+	defer func() { os.Args = args }()
+	errNotFound := errors.New("not found")
+	tests := []struct {
+		args []string
+		want int
+	}{
+		{[]string{"appname", "missing"}, 4},
+		{[]string{"appname", "test"}, 64},
+		{[]string{"appname", "code"}, 3},
+		{[]string{"appname", "fail"}, ExitFailure},
+	}
+	for _, tt := range tests {
+		have := -1
+		exit := ExitFunc(func(code int) { have = code })
+		opts := []Option{
+			ExitCodeFor(errNotFound, 4),
+			ExitCodeFor(ErrUsage, 64),
+			exit,
+			Resolver(func(error) {}),
+			Stderr(io.Discard),
+		}
+		app := New("appname", newTestUsage(t), nil, opts...)
+		app.Add("missing", func(args []string) error {
+			return fmt.Errorf("lookup: %w", errNotFound)
+		}, nil)
+		app.Add("test", testCommandErrUsage, nil)
+		app.Add("fail", testCommandFailure, nil)
+		app.Add("code", func(args []string) error {
+			return ExitError{Code: 3, Err: errNotFound}
+		}, nil)
+		os.Args = tt.args
+		app.Main()
+		if have != tt.want {
+			t.Errorf("%v\nhave %d\nwant %d", tt.args, have, tt.want)
+		}
+	}
+}
+
+type testExitError struct{}
+
+func (e *testExitError) Error() string {
+	return "test exit error"
+}
+
+func TestExitCodeAs(t *testing.T) {
+	args := os.Args
+	defer func() { os.Args = args }()
+	tests := []struct {
+		args []string
+		want int
+	}{
+		{[]string{"appname", "config"}, 78},
+		{[]string{"appname", "custom"}, 5},
+		{[]string{"appname", "--undefined", "custom"}, ExitUsage},
+		{[]string{"appname", "fail"}, ExitFailure},
+	}
+	for _, tt := range tests {
+		have := -1
+		exit := ExitFunc(func(code int) { have = code })
+		opts := []Option{
+			ExitCodeAs(new(ErrInvalidConfig), 78),
+			ExitCodeAs(new(*testExitError), 5),
+			exit,
+			Resolver(func(error) {}),
+			Stderr(io.Discard),
+		}
+		app := New("appname", newTestUsage(t), nil, opts...)
+		app.Add("config", func(args []string) error {
+			return fmt.Errorf("load: %w", ErrInvalidConfig{Path: "config", Err: errors.New("bad")})
+		}, nil)
+		app.Add("custom", func(args []string) error {
+			return fmt.Errorf("run: %w", &testExitError{})
+		}, nil)
+		app.Add("fail", testCommandFailure, nil)
+		os.Args = tt.args
+		app.Main()
+		if have != tt.want {
+			t.Errorf("%v\nhave %d\nwant %d", tt.args, have, tt.want)
+		}
+	}
+}
+
+func TestExitCodeAsPanic(t *testing.T) {
+	tests := []interface{}{
+		nil,
+		ErrInvalidConfig{},
+		new(string),
+		(*ErrInvalidConfig)(nil),
+	}
+	for _, target := range tests {
+		func() {
+			defer func() {
+				perr := recover()
+				if perr == nil {
+					t.Fatalf("ExitCodeAs(%#v) should panic", target)
+				}
+			}()
+			ExitCodeAs(target, 1)
+		}()
+	}
+}
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"fmt"
 	"reflect"
 	"strings"
 )
@@ -8,34 +9,113 @@ import (
 // Flag represents a flag.
 type Flag struct {
 	flag         reflect.Value
-	kind         FlagKind
+	initial      reflect.Value
+	kind         FlagKindE
 	name         string
 	alias        string
 	count        int
 	value        string
+	usage        string
 	envKey       string
 	defaultValue string
+	sep          string
+	reset        bool
+	required     bool
+	negatable    bool
+	choices      []string
+	source       Source
 }
 
-// NewFlag returns a new flag. The flag must be a pointer. You must pass the
-// Kind option so the flag parser knows how to process the command line unless
-// the flag points to a string, the default flag kind.
+// NewFlag returns a new flag. The flag must be a pointer or implement the
+// flag.Value interface. The flag kind is inferred from the type the flag points
+// to. Built in kinds exist for strings, booleans, integers, floats, durations,
+// []string, map[string]string and types implementing flag.Value or
+// encoding.TextUnmarshaler. You must pass the Kind option for any other
+// type or NewFlag panics.
 func NewFlag(name string, flag interface{}, opts ...FlagOption) *Flag {
 	v := reflect.ValueOf(flag)
-	if v.Kind() != reflect.Ptr {
-		panic("cli: flag must be pointer")
-	}
 	f := &Flag{
-		flag: v.Elem(),
-		kind: flagString{},
-		name: strings.ToLower(name),
+		name:  strings.ToLower(name),
+		sep:   ",",
+		reset: true,
+	}
+	if v.Kind() == reflect.Ptr {
+		f.flag = v.Elem()
+		f.kind = kindOf(f.flag)
+	} else if v.IsValid() && v.Type().Implements(valueType) {
+		f.kind = valueKind(v)
+	} else {
+		panic("cli: flag must be pointer")
 	}
 	for _, option := range opts {
 		option(f)
 	}
+	if f.kind == nil {
+		panic(fmt.Errorf("cli: flag '%s' has no built in kind for %s, use the Kind option", f.name, v.Type()))
+	}
+	if f.negatable && f.kind.HasArg() {
+		panic(fmt.Errorf("cli: flag '%s' is negatable but requires an argument", f.name))
+	}
+	if f.defaultValue != "" {
+		err := f.load(f.defaultValue)
+		if err != nil {
+			panic(fmt.Errorf("cli: invalid default value: %v", err))
+		}
+		f.source = Source{Kind: SourceDefault, Value: f.defaultValue}
+	}
+	if f.flag.IsValid() {
+		f.initial = reflect.New(f.flag.Type()).Elem()
+		f.initial.Set(f.flag)
+	}
 	return f
 }
 
+// restore resets the flag to the state returned by NewFlag. The
+// value the flag pointed to is restored and the default value is
+// loaded again. Flags of flag.Value types are only restored from
+// the value they pointed to, as setting the default value again
+// would accumulate in values that append, and flag.Value types
+// that are not pointers cannot be restored at all.
+func (f *Flag) restore() error {
+	if f.flag.IsValid() {
+		f.flag.Set(f.initial)
+	}
+	f.count = 0
+	f.value = ""
+	f.reset = true
+	f.source = Source{}
+	if f.defaultValue == "" {
+		return nil
+	}
+	_, ok := f.kind.(flagValue)
+	if ok {
+		f.value = f.defaultValue
+		f.source = Source{Kind: SourceDefault, Value: f.defaultValue}
+		return nil
+	}
+	err := f.load(f.defaultValue)
+	if err != nil {
+		return err
+	}
+	f.source = Source{Kind: SourceDefault, Value: f.defaultValue}
+	return nil
+}
+
+// checkRequired returns an ErrMissingFlags listing every required
+// flag that was neither set nor given a default value.
+func checkRequired(flags []*Flag) error {
+	var missing ErrMissingFlags
+	for _, f := range flags {
+		if f.required && !f.IsSet() && f.defaultValue == "" {
+			missing = append(missing, f.name)
+		}
+	}
+	if len(missing) > 0 {
+		return missing
+	}
+	return nil
+}
+
 // Count returns the number of times the flag was set.
 func (f *Flag) Count() int {
 	return f.count
@@ -46,11 +126,169 @@ func (f *Flag) IsSet() bool {
 	return f.count > 0
 }
 
-// Set sets the flag value.
-func (f *Flag) Set(value string) {
+// Set sets the flag value. An error of type ErrInvalidFlagValue
+// is returned if the flag kind is unable to parse the value.
+//
+// Accumulating flag kinds, such as Strings and StringMap, append
+// the value to the values set on the command line. The first value
+// set on the command line replaces environment and default values.
+func (f *Flag) Set(value string) error {
+	err := f.set(value)
+	if err != nil {
+		return err
+	}
 	f.count++
-	f.flag.Set(reflect.ValueOf(f.kind.Parse(value)))
-	f.value = value
+	f.source = Source{Kind: SourceCommandLine, Value: f.value}
+	return nil
+}
+
+// set sets the flag value without counting the set.
+func (f *Flag) set(value string) error {
+	v, err := f.parse(value)
+	if err != nil {
+		return err
+	}
+	_, ok := f.kind.(flagAppender)
+	if ok && !f.reset {
+		f.value += f.sep + value
+	} else {
+		f.value = value
+	}
+	if v.IsValid() {
+		f.flag.Set(v)
+	}
+	f.reset = false
+	return nil
+}
+
+// load replaces the flag value with value. Values of accumulating
+// flag kinds are split by the flag separator.
+func (f *Flag) load(value string) error {
+	_, ok := f.kind.(flagAppender)
+	if !ok {
+		return f.loadValues([]string{value})
+	}
+	if value == "" {
+		return f.loadValues(nil)
+	}
+	return f.loadValues(strings.Split(value, f.sep))
+}
+
+// loadValues replaces the flag value with values. The next
+// value set on the command line will replace the loaded values.
+func (f *Flag) loadValues(values []string) error {
+	f.reset = true
+	if len(values) == 0 && f.flag.IsValid() {
+		f.flag.Set(reflect.Zero(f.flag.Type()))
+		f.value = ""
+	}
+	for _, v := range values {
+		err := f.set(v)
+		if err != nil {
+			return err
+		}
+	}
+	f.reset = true
+	return nil
+}
+
+// parse returns the value as parsed by the flag kind. An error of
+// type ErrInvalidFlagValue is returned if the kind is unable to parse
+// the value or returns a value not assignable to the flag.
+func (f *Flag) parse(value string) (reflect.Value, error) {
+	err := f.choose(value)
+	if err != nil {
+		return reflect.Value{}, ErrInvalidFlagValue{Flag: f.name, Value: value, Err: err}
+	}
+	var v interface{}
+	a, ok := f.kind.(flagAppender)
+	if ok && !f.reset {
+		v, err = a.append(f.flag.Interface(), value)
+	} else {
+		v, err = f.kind.Parse(value)
+	}
+	if err != nil {
+		return reflect.Value{}, ErrInvalidFlagValue{Flag: f.name, Value: value, Err: err}
+	}
+	if !f.flag.IsValid() {
+		return reflect.Value{}, nil
+	}
+	t := f.flag.Type()
+	if v == nil {
+		return reflect.Zero(t), nil
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == t.Kind() && rv.Type().ConvertibleTo(t) {
+		rv = rv.Convert(t)
+	}
+	if !rv.Type().AssignableTo(t) {
+		err = fmt.Errorf("flag kind returned %s, want %s", rv.Type(), t)
+		return reflect.Value{}, ErrInvalidFlagValue{Flag: f.name, Value: value, Err: err}
+	}
+	return rv, nil
+}
+
+// choose returns an error if the flag has a set of allowed values
+// and value is not one of them. The error suggests the most similar
+// allowed value by levenshtein distance.
+func (f *Flag) choose(value string) error {
+	if len(f.choices) == 0 {
+		return nil
+	}
+	suggestion := ""
+	distance := similarThreshold
+	for _, choice := range f.choices {
+		if value == choice {
+			return nil
+		}
+		d := levenshtein(value, choice)
+		if d < distance {
+			suggestion, distance = choice, d
+		}
+	}
+	list := strings.Join(f.choices, ", ")
+	if suggestion == "" {
+		return fmt.Errorf("allowed values are %s", list)
+	}
+	return fmt.Errorf("did you mean '%s'? Allowed values are %s", suggestion, list)
+}
+
+// Source returns the source of the flag value. Values set by
+// the Set method are considered to be set on the command line.
+func (f *Flag) Source() Source {
+	return f.source
+}
+
+// effective returns the effective flag value for display.
+func (f *Flag) effective() string {
+	if !f.flag.IsValid() {
+		return f.value
+	}
+	return fmt.Sprint(f.flag.Interface())
+}
+
+// Forms returns the command line forms of the flag for help and
+// completion output. The long form is followed by the negated form
+// of negatable flags and the short form if the flag has an alias.
+func (f *Flag) Forms() []string {
+	forms := []string{"--" + f.name}
+	if f.negatable {
+		forms = append(forms, "--no-"+f.name)
+	}
+	if f.alias != "" {
+		forms = append(forms, "-"+f.alias)
+	}
+	return forms
+}
+
+// Choices returns the allowed values or nil if any value is allowed.
+func (f *Flag) Choices() []string {
+	if len(f.choices) == 0 {
+		return nil
+	}
+	rv := make([]string, len(f.choices))
+	copy(rv, f.choices)
+	return rv
 }
 
 // String returns the value as a string. Boolean flags are
@@ -70,48 +308,33 @@ type FlagKind interface {
 	HasArg() bool
 }
 
-// flagString represents a string flag.
-type flagString struct{}
-
-// Parse returns the value as-is.
-//
-// Parse implements the FlagKind interface.
-func (f flagString) Parse(value string) interface{} {
-	return value
-}
-
-// HasArg implements the FlagKind interface.
-func (f flagString) HasArg() bool {
-	return true
+// FlagKindE represents the type of flag that may fail to parse.
+type FlagKindE interface {
+	Parse(value string) (interface{}, error)
+	HasArg() bool
 }
 
-// flagBool represents a boolean flag.
-type flagBool struct{}
-
-// Parse returns "true" if the value is
-// 1, t, T, true, TRUE, True, y, Y, yes, YES, Yes.
-//
-// Parse implements the FlagKind interface.
-func (f flagBool) Parse(value string) interface{} {
-	switch value {
-	case "1", "t", "T", "true", "TRUE", "True", "y", "Y", "yes", "YES", "Yes":
-		return true
-	}
-	return false
+// flagKind adapts a FlagKind to the FlagKindE interface.
+type flagKind struct {
+	FlagKind
 }
 
-// HasArg implements the FlagKind interface.
-func (f flagBool) HasArg() bool {
-	return false
+// Parse implements the FlagKindE interface.
+func (k flagKind) Parse(value string) (interface{}, error) {
+	return k.FlagKind.Parse(value), nil
 }
 
 // FlagOption represents a functional option for flag configuration.
 type FlagOption func(*Flag)
 
-// Kind sets the flag kind.
-// This option is required unless the flag points to a string.
-// This option must be used before the DefaultValue option.
+// Kind sets the flag kind. This option is required
+// unless the flag points to a type with a built in kind.
 func Kind(kind FlagKind) FlagOption {
+	return KindE(flagKind{kind})
+}
+
+// KindE sets the flag kind to a kind that may fail to parse.
+func KindE(kind FlagKindE) FlagOption {
 	return func(f *Flag) {
 		f.kind = kind
 	}
@@ -119,7 +342,46 @@ func Kind(kind FlagKind) FlagOption {
 
 // Bool sets the flag kind to the built in boolean flag kind.
 func Bool() FlagOption {
-	return Kind(flagBool{})
+	return KindE(flagBool{})
+}
+
+// Int sets the flag kind to the built in int flag kind.
+func Int() FlagOption {
+	return KindE(flagInt{})
+}
+
+// Int64 sets the flag kind to the built in int64 flag kind.
+func Int64() FlagOption {
+	return KindE(flagInt64{})
+}
+
+// Uint sets the flag kind to the built in uint flag kind.
+func Uint() FlagOption {
+	return KindE(flagUint{})
+}
+
+// Float64 sets the flag kind to the built in float64 flag kind.
+func Float64() FlagOption {
+	return KindE(flagFloat64{})
+}
+
+// Strings sets the flag kind to the built in []string flag
+// kind. Values accumulate each time the flag is set.
+func Strings() FlagOption {
+	return KindE(flagStrings{})
+}
+
+// StringMap sets the flag kind to the built in map[string]string
+// flag kind. Values are given as key=value pairs and accumulate
+// each time the flag is set.
+func StringMap() FlagOption {
+	return KindE(flagStringMap{})
+}
+
+// Duration sets the flag kind to the built in time.Duration
+// flag kind. Values are parsed by time.ParseDuration.
+func Duration() FlagOption {
+	return KindE(flagDuration{})
 }
 
 // ShortFlag sets the short flag.
@@ -129,15 +391,55 @@ func ShortFlag(name string) FlagOption {
 	}
 }
 
-// DefaultValue sets the flag default value.
+// DefaultValue sets the flag default value. The default value
+// is parsed by the flag kind after all options are applied.
+// NewFlag panics if the value cannot be parsed.
 func DefaultValue(value string) FlagOption {
 	return func(f *Flag) {
-		f.flag.Set(reflect.ValueOf(f.kind.Parse(value)))
-		f.value = value
 		f.defaultValue = value
 	}
 }
 
+// OneOf restricts the flag to the allowed values. Values
+// are checked before they are parsed by the flag kind.
+func OneOf(values ...string) FlagOption {
+	return func(f *Flag) {
+		f.choices = values
+	}
+}
+
+// Negatable allows a boolean flag to be set to false on the command
+// line with the "no-" prefix, such as "--no-color" for "color".
+// NewFlag panics if the flag requires an argument.
+func Negatable() FlagOption {
+	return func(f *Flag) {
+		f.negatable = true
+	}
+}
+
+// Required requires the flag to be set on the command line or by
+// its environment variable unless the flag has a default value.
+func Required() FlagOption {
+	return func(f *Flag) {
+		f.required = true
+	}
+}
+
+// Separator sets the separator used to split environment and
+// default values of accumulating flag kinds. Defaults to ",".
+func Separator(sep string) FlagOption {
+	return func(f *Flag) {
+		f.sep = sep
+	}
+}
+
+// Usage sets the flag usage text for help output.
+func Usage(text string) FlagOption {
+	return func(f *Flag) {
+		f.usage = text
+	}
+}
+
 // EnvironmentKey sets the flag environment variable key.
 func EnvironmentKey(key string) FlagOption {
 	return func(f *Flag) {
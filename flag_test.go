@@ -1,6 +1,12 @@
 package cli
 
-import "testing"
+import (
+	"net"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
 
 func TestNewFlag(t *testing.T) {
 	var flag string
@@ -24,6 +30,17 @@ func TestNewFlagPanic(t *testing.T) {
 	_ = NewFlag("flag", flag)
 }
 
+func TestFlagNegatablePanic(t *testing.T) {
+	var flag string
+	defer func() {
+		perr := recover()
+		if perr == nil {
+			t.Fatal("should panic")
+		}
+	}()
+	_ = NewFlag("flag", &flag, Negatable())
+}
+
 func TestFlagSet(t *testing.T) {
 	var flag string
 	f := NewFlag("flag", &flag)
@@ -46,3 +63,159 @@ func TestFlagSetCount(t *testing.T) {
 		t.Fatal("should increment set count")
 	}
 }
+
+func TestFlagDefaultValuePanic(t *testing.T) {
+	var flag int
+	defer func() {
+		perr := recover()
+		if perr == nil {
+			t.Fatal("should panic")
+		}
+	}()
+	_ = NewFlag("flag", &flag, Int(), DefaultValue("abc"))
+}
+
+func TestFlagStrings(t *testing.T) {
+	var tags []string
+	f := NewFlag("tag", &tags, Strings(), DefaultValue("x,y"))
+	if !reflect.DeepEqual(tags, []string{"x", "y"}) {
+		t.Fatalf("default\nhave %v\nwant %v", tags, []string{"x", "y"})
+	}
+	_, err := Parse([]string{"--tag", "a", "--tag=b"}, []*Flag{f})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"a", "b"}
+	if !reflect.DeepEqual(tags, want) {
+		t.Fatalf("tags\nhave %v\nwant %v", tags, want)
+	}
+	if f.String() != "a,b" || f.Count() != 2 {
+		t.Fatalf("flag\nhave '%s' %d\nwant '%s' %d", f.String(), f.Count(), "a,b", 2)
+	}
+}
+
+func TestFlagStringMap(t *testing.T) {
+	var labels map[string]string
+	f := NewFlag("label", &labels, StringMap())
+	_, err := Parse([]string{"--label", "a=1", "--label", "b=2=3"}, []*Flag{f})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]string{"a": "1", "b": "2=3"}
+	if !reflect.DeepEqual(labels, want) {
+		t.Fatalf("labels\nhave %v\nwant %v", labels, want)
+	}
+	_, err = Parse([]string{"--label", "invalid"}, []*Flag{f})
+	if err == nil {
+		t.Fatal("should error without key=value")
+	}
+}
+
+func TestFlagStringsEnv(t *testing.T) {
+	const env = "TEST_FLAG_STRINGS_ENV"
+	t.Setenv(env, "a;b")
+	var tags []string
+	flags := []*Flag{NewFlag("tag", &tags, Strings(), Separator(";"), EnvironmentKey(env))}
+	_, err := Parse(nil, flags)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(tags, []string{"a", "b"}) {
+		t.Fatalf("env\nhave %v\nwant %v", tags, []string{"a", "b"})
+	}
+	_, err = Parse([]string{"--tag", "c"}, flags)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(tags, []string{"c"}) {
+		t.Fatalf("command line should replace env\nhave %v\nwant %v", tags, []string{"c"})
+	}
+}
+
+func TestFlagOneOf(t *testing.T) {
+	var format string
+	f := NewFlag("format", &format, OneOf("json", "yaml", "table"), DefaultValue("table"))
+	err := f.Set("yaml")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = f.Set("jsno")
+	if err == nil {
+		t.Fatal("should reject values outside the allowed set")
+	}
+	have := err.Error()
+	want := "Flag 'format' has invalid value 'jsno': did you mean 'json'? Allowed values are json, yaml, table."
+	if have != want {
+		t.Fatalf("error\nhave %s\nwant %s", have, want)
+	}
+	if format != "yaml" {
+		t.Fatalf("format\nhave %s\nwant %s", format, "yaml")
+	}
+	if !reflect.DeepEqual(f.Choices(), []string{"json", "yaml", "table"}) {
+		t.Fatalf("choices\nhave %v", f.Choices())
+	}
+}
+
+func TestFlagForms(t *testing.T) {
+	var color bool
+	f := NewFlag("color", &color, Bool(), Negatable(), ShortFlag("c"))
+	want := []string{"--color", "--no-color", "-c"}
+	if !reflect.DeepEqual(f.Forms(), want) {
+		t.Fatalf("forms\nhave %v\nwant %v", f.Forms(), want)
+	}
+}
+
+type testLevel int
+
+func (l *testLevel) String() string {
+	return strconv.Itoa(int(*l))
+}
+
+func (l *testLevel) Set(value string) error {
+	*l = testLevel(len(value))
+	return nil
+}
+
+func TestNewFlagInference(t *testing.T) {
+	var (
+		b   bool
+		i8  int8
+		u16 uint16
+		f32 float32
+		d   time.Duration
+		ip  net.IP
+		lvl testLevel
+	)
+	flags := []*Flag{
+		NewFlag("b", &b),
+		NewFlag("i8", &i8),
+		NewFlag("u16", &u16),
+		NewFlag("f32", &f32),
+		NewFlag("d", &d),
+		NewFlag("ip", &ip),
+		NewFlag("lvl", &lvl),
+	}
+	args := []string{"-b", "-i8=-8", "-u16", "16", "-f32", "3.5", "-d", "1s", "-ip", "127.0.0.1", "-lvl", "high"}
+	_, err := Parse(args, flags)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !b || i8 != -8 || u16 != 16 || f32 != 3.5 || d != time.Second || !ip.Equal(net.IPv4(127, 0, 0, 1)) || lvl != 4 {
+		t.Fatalf("flags not inferred: %v %v %v %v %v %v %v", b, i8, u16, f32, d, ip, lvl)
+	}
+	_, err = Parse([]string{"-i8", "128"}, flags)
+	if err == nil {
+		t.Fatal("should reject out of range values")
+	}
+}
+
+func TestNewFlagInferencePanic(t *testing.T) {
+	var flag chan int
+	defer func() {
+		perr := recover()
+		if perr == nil {
+			t.Fatal("should panic")
+		}
+	}()
+	_ = NewFlag("flag", &flag)
+}
@@ -0,0 +1,95 @@
+package cli
+
+import "strings"
+
+// flagGroup represents a constraint over a group of flags.
+type flagGroup struct {
+	exclusive bool
+	flags     []*Flag
+}
+
+// check returns an error if the group constraint is violated.
+// Values from the configuration file are not considered set as
+// they have the lowest precedence, like default values.
+func (g flagGroup) check() error {
+	var set []string
+	for _, f := range g.flags {
+		if f.IsSet() && f.source.Kind != SourceConfig {
+			set = append(set, f.name)
+		}
+	}
+	if g.exclusive {
+		if len(set) > 1 {
+			return ErrMutuallyExclusive(set)
+		}
+		return nil
+	}
+	if len(set) > 0 && len(set) < len(g.flags) {
+		return ErrRequiredTogether(g.names())
+	}
+	return nil
+}
+
+// names returns the names of the flags in the group.
+func (g flagGroup) names() []string {
+	rv := make([]string, len(g.flags))
+	for i, f := range g.flags {
+		rv[i] = f.name
+	}
+	return rv
+}
+
+// String returns a description of the constraint for help output.
+//
+// String implements the fmt.Stringer interface.
+func (g flagGroup) String() string {
+	return g.message(nil)
+}
+
+// message returns the description translated by cat.
+func (g flagGroup) message(cat Catalog) string {
+	names := g.names()
+	for i, name := range names {
+		names[i] = "--" + name
+	}
+	if g.exclusive {
+		return cat.sprintf("%s are mutually exclusive", joinList(cat, names))
+	}
+	return cat.sprintf("%s must be used together", joinList(cat, names))
+}
+
+// checkGroups returns the first violated group constraint.
+func checkGroups(groups []flagGroup) error {
+	for _, g := range groups {
+		err := g.check()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// joinList joins items as a list translated by cat.
+func joinList(cat Catalog, items []string) string {
+	if len(items) < 2 {
+		return strings.Join(items, "")
+	}
+	return cat.sprintf("%s and %s", strings.Join(items[:len(items)-1], ", "), items[len(items)-1])
+}
+
+// MutuallyExclusive declares that at most one of the flags may be set.
+// Values from the configuration file are ignored by the check.
+func MutuallyExclusive(flags ...*Flag) CommandOption {
+	return func(c *Command) {
+		c.groups = append(c.groups, flagGroup{exclusive: true, flags: flags})
+	}
+}
+
+// RequiredTogether declares that if any of the flags are set
+// then all of the flags must be set. Values from the
+// configuration file are ignored by the check.
+func RequiredTogether(flags ...*Flag) CommandOption {
+	return func(c *Command) {
+		c.groups = append(c.groups, flagGroup{flags: flags})
+	}
+}
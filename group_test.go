@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFlagGroups(t *testing.T) {
+	tests := map[string]error{
+		"":                        nil,
+		"--file f":                nil,
+		"--stdin":                 nil,
+		"--file f --stdin":        ErrMutuallyExclusive{"file", "stdin"},
+		"--cert c --key k":        nil,
+		"--cert c":                ErrRequiredTogether{"cert", "key"},
+		"--key k":                 ErrRequiredTogether{"cert", "key"},
+		"--file f --cert c --key": ErrRequiresArg("key"),
+	}
+	for line, want := range tests {
+		var file, cert, key string
+		var stdin bool
+		fileFlag := NewFlag("file", &file)
+		stdinFlag := NewFlag("stdin", &stdin, Bool())
+		certFlag := NewFlag("cert", &cert)
+		keyFlag := NewFlag("key", &key)
+		flags := []*Flag{fileFlag, stdinFlag, certFlag, keyFlag}
+		app := New("appname", newTestUsage(t), nil, Resolver(func(error) {}))
+		app.Add("test", testCommand, flags,
+			MutuallyExclusive(fileFlag, stdinFlag),
+			RequiredTogether(certFlag, keyFlag),
+		)
+		args := append([]string{"appname", "test"}, strings.Fields(line)...)
+		err := app.Run(args)
+		if !reflect.DeepEqual(err, want) {
+			t.Fatalf("error for '%s'\nhave %v\nwant %v", line, err, want)
+		}
+	}
+}
+
+func TestFlagGroupsUsage(t *testing.T) {
+	var buf bytes.Buffer
+	var file, cert, key string
+	var stdin bool
+	fileFlag := NewFlag("file", &file)
+	stdinFlag := NewFlag("stdin", &stdin, Bool())
+	certFlag := NewFlag("cert", &cert)
+	keyFlag := NewFlag("key", &key)
+	flags := []*Flag{fileFlag, stdinFlag, certFlag, keyFlag}
+	app := New("appname", newTestUsage(t), nil, Stdout(&buf), Stderr(io.Discard))
+	app.Add("test", testCommand, flags,
+		MutuallyExclusive(fileFlag, stdinFlag),
+		RequiredTogether(certFlag, keyFlag),
+	)
+	err := app.Run([]string{"appname", "help", "test"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	have := buf.String()
+	want := "test.md\n\nFlag constraints:\n\n    --file and --stdin are mutually exclusive\n    --cert and --key must be used together\n"
+	if have != want {
+		t.Fatalf("usage\nhave '%s'\nwant '%s'", have, want)
+	}
+}
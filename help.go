@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// writeHelp writes a help page generated from the command and flag
+// metadata to w. The root help page is written if cmd is nil.
+func (c *CLI) writeHelp(w io.Writer, cmd *Command) error {
+	var b strings.Builder
+	description := c.description
+	commands := c.commands
+	flags := c.flags
+	var global []*Flag
+	if cmd != nil {
+		description = cmd.description
+		commands = cmd.commands
+		flags = cmd.flags
+		global = c.parentFlags(cmd)
+	}
+	fmt.Fprintf(&b, "%s\n", c.catalog.sprintf("Usage: %s", c.synopsis(cmd)))
+	if description != "" {
+		fmt.Fprintf(&b, "\n%s\n", description)
+	}
+	if len(commands) > 0 {
+		fmt.Fprintf(&b, "\n%s\n\n", c.catalog.sprintf("Commands:"))
+		writeCommands(&b, commands)
+	}
+	if len(flags) > 0 {
+		fmt.Fprintf(&b, "\n%s\n\n", c.catalog.sprintf("Flags:"))
+		c.writeFlags(&b, flags)
+	}
+	if len(global) > 0 {
+		fmt.Fprintf(&b, "\n%s\n\n", c.catalog.sprintf("Global flags:"))
+		c.writeFlags(&b, global)
+	}
+	if len(commands) > 0 {
+		topic := c.name + " help"
+		if cmd != nil {
+			topic += " " + strings.ReplaceAll(cmd.path, "/", " ")
+		}
+		fmt.Fprintf(&b, "\n%s\n", c.catalog.sprintf("Run '%s [command]' for more information about a command.", topic))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// synopsis returns the command line synopsis of the command.
+func (c *CLI) synopsis(cmd *Command) string {
+	parts := []string{c.name}
+	if len(c.flags) > 0 {
+		parts = append(parts, "[flags]")
+	}
+	commands := c.commands
+	if cmd != nil {
+		parts = append(parts, strings.Split(cmd.path, "/")...)
+		if len(cmd.flags) > 0 {
+			parts = append(parts, "[flags]")
+		}
+		commands = cmd.commands
+	}
+	if len(commands) > 0 {
+		parts = append(parts, "<command>")
+	} else if cmd != nil && cmd.args != nil {
+		args := cmd.args.synopsis()
+		if args != "" {
+			parts = append(parts, args)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// parentFlags returns the flags of the application and
+// every command above cmd in the command tree.
+func (c *CLI) parentFlags(cmd *Command) []*Flag {
+	flags := c.flags[:len(c.flags):len(c.flags)]
+	names := strings.Split(cmd.path, "/")
+	commands := c.commands
+	for _, name := range names[:len(names)-1] {
+		parent := commands[name]
+		flags = append(flags, parent.flags...)
+		commands = parent.commands
+	}
+	return flags
+}
+
+// writeCommands writes a table of commands sorted by name.
+// Aliases are listed with the command they refer to.
+func writeCommands(w io.Writer, commands map[string]*Command) {
+	var names []string
+	for name, cmd := range commands {
+		if name == cmd.name {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	tw := tabwriter.NewWriter(w, 0, 4, 4, ' ', 0)
+	for _, name := range names {
+		cmd := commands[name]
+		if cmd.alias != "" {
+			name += ", " + cmd.alias
+		}
+		fmt.Fprintf(tw, "    %s\t%s\n", name, cmd.description)
+	}
+	tw.Flush()
+}
+
+// writeFlags writes a table of flags with their forms, usage
+// text, default values, allowed values and environment keys.
+func (c *CLI) writeFlags(w io.Writer, flags []*Flag) {
+	tw := tabwriter.NewWriter(w, 0, 4, 4, ' ', 0)
+	for _, f := range flags {
+		forms := strings.Join(f.Forms(), ", ")
+		if f.alias != "" {
+			forms = "-" + f.alias + ", " + strings.TrimSuffix(forms, ", -"+f.alias)
+		}
+		if f.kind.HasArg() {
+			forms += " <value>"
+		}
+		var details []string
+		if len(f.choices) > 0 {
+			details = append(details, c.catalog.sprintf("one of: %s", strings.Join(f.choices, ", ")))
+		}
+		if f.defaultValue != "" {
+			details = append(details, c.catalog.sprintf("default: %s", f.defaultValue))
+		}
+		details = append(details, c.catalog.sprintf("env: %s", c.flagEnvKey(f)))
+		usage := f.usage
+		if usage != "" {
+			usage += " "
+		}
+		usage += "(" + strings.Join(details, "; ") + ")"
+		fmt.Fprintf(tw, "    %s\t%s\n", forms, usage)
+	}
+	tw.Flush()
+}
+
+// flagEnvKey returns the environment variable key of the flag.
+// The key is computed for flags that have not yet been parsed.
+func (c *CLI) flagEnvKey(f *Flag) string {
+	if f.envKey != "" {
+		return f.envKey
+	}
+	return c.envKey(f.name)
+}
@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func newTestHelpApp(t *testing.T, stdout io.Writer, opts ...Option) *CLI {
+	t.Helper()
+	var verbose bool
+	var port int
+	var format string
+	flags := []*Flag{
+		NewFlag("verbose", &verbose, ShortFlag("v"), Negatable(), Usage("Enable verbose output.")),
+	}
+	opts = append([]Option{About("Manage the things."), Stdout(stdout), Stderr(io.Discard)}, opts...)
+	app := New("appname", nil, flags, opts...)
+	cluster := app.Add("cluster", testCommand, nil, Description("Manage clusters."), Alias("c"))
+	p := NewFlag("port", &port, DefaultValue("80"), Usage("Node port."))
+	f := NewFlag("format", &format, OneOf("json", "yaml"))
+	cluster.Add("drain", testCommand, []*Flag{p, f},
+		Description("Drain a node."),
+		Args("node", "[reason...]"),
+		MutuallyExclusive(p, f),
+	)
+	return app
+}
+
+func TestHelpGenerated(t *testing.T) {
+	var tests = []struct {
+		args []string
+		want string
+	}{
+		{
+			[]string{"appname", "help"},
+			`Usage: appname [flags] <command>
+
+Manage the things.
+
+Commands:
+
+    cluster, c    Manage clusters.
+    help          Show usage information for a command.
+
+Flags:
+
+    -v, --verbose, --no-verbose    Enable verbose output. (env: APPNAME_VERBOSE)
+
+Run 'appname help [command]' for more information about a command.
+`,
+		},
+		{
+			[]string{"appname", "help", "cluster", "drain"},
+			`Usage: appname [flags] cluster drain [flags] <node> [reason]...
+
+Drain a node.
+
+Flags:
+
+    --port <value>      Node port. (default: 80; env: APPNAME_PORT)
+    --format <value>    (one of: json, yaml; env: APPNAME_FORMAT)
+
+Global flags:
+
+    -v, --verbose, --no-verbose    Enable verbose output. (env: APPNAME_VERBOSE)
+
+Flag constraints:
+
+    --port and --format are mutually exclusive
+`,
+		},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		app := newTestHelpApp(t, &buf)
+		err := app.Run(tt.args)
+		if err != nil {
+			t.Fatalf("help %v\nunexpected error: %v", tt.args, err)
+		}
+		have := buf.String()
+		if have != tt.want {
+			t.Fatalf("help %v\nhave\n%s\nwant\n%s", tt.args, have, tt.want)
+		}
+	}
+}
+
+func TestHelpGeneratedUnknown(t *testing.T) {
+	app := newTestHelpApp(t, io.Discard)
+	err := app.Run([]string{"appname", "help", "not-found"})
+	if err != ErrExitFailure {
+		t.Fatalf("help for unknown topic should error")
+	}
+}
+
+func TestHelpUnknownSubcommand(t *testing.T) {
+	var tests = []struct {
+		args []string
+		want string
+	}{
+		{
+			[]string{"appname", "help", "cluster", "dran"},
+			"Unknown command 'dran'.\nRun 'appname help cluster' for usage information.\n\nDid you mean?\n\n    drain\n\n",
+		},
+		{
+			[]string{"appname", "help", "cluster", "drain", "extra"},
+			"Too many arguments given.\n",
+		},
+	}
+	for _, tt := range tests {
+		var stderr bytes.Buffer
+		app := newTestHelpApp(t, io.Discard, Stderr(&stderr))
+		err := app.Run(tt.args)
+		if err != ErrExitFailure {
+			t.Fatalf("%v\nhave %v\nwant %v", tt.args, err, ErrExitFailure)
+		}
+		if !strings.HasPrefix(stderr.String(), tt.want) {
+			t.Fatalf("%v\nhave '%s'\nwant '%s'", tt.args, stderr.String(), tt.want)
+		}
+	}
+}
+
+func TestArgsSynopsis(t *testing.T) {
+	var tests = []struct {
+		spec *argSpec
+		want string
+	}{
+		{&argSpec{names: []string{"src", "dst"}, min: 2, max: 2}, "<src> <dst>"},
+		{&argSpec{names: []string{"file"}, min: 1, max: -1}, "<file>..."},
+		{&argSpec{names: []string{"file"}, min: 0, max: -1}, "[file]..."},
+		{&argSpec{min: 1, max: 2}, "<arg1> [arg2]"},
+		{&argSpec{min: 0, max: 0}, ""},
+	}
+	for _, tt := range tests {
+		have := tt.spec.synopsis()
+		if have != tt.want {
+			t.Errorf("synopsis %+v\nhave '%s'\nwant '%s'", tt.spec, have, tt.want)
+		}
+	}
+}
@@ -0,0 +1,377 @@
+package cli
+
+import (
+	"encoding"
+	"errors"
+	"flag"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	valueType           = reflect.TypeOf((*flag.Value)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// kindOf returns the built in flag kind for the value v
+// or nil if there is no built in kind for its type.
+func kindOf(v reflect.Value) FlagKindE {
+	t := v.Type()
+	if v.CanAddr() && reflect.PtrTo(t).Implements(valueType) {
+		return newFlagValue(v.Addr().Interface().(flag.Value))
+	}
+	if reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		return flagText{t}
+	}
+	if t == durationType {
+		return flagDuration{}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return flagString{}
+	case reflect.Bool:
+		return flagBool{}
+	case reflect.Int:
+		return flagInt{}
+	case reflect.Int64:
+		return flagInt64{}
+	case reflect.Uint:
+		return flagUint{}
+	case reflect.Float64:
+		return flagFloat64{}
+	case reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32:
+		return flagNumber{t}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.String {
+			return flagStrings{}
+		}
+	case reflect.Map:
+		if t.Key().Kind() == reflect.String && t.Elem().Kind() == reflect.String {
+			return flagStringMap{}
+		}
+	}
+	return nil
+}
+
+// flagAppender is implemented by flag kinds that accumulate
+// values each time the flag is set rather than replacing them.
+type flagAppender interface {
+	append(current interface{}, value string) (interface{}, error)
+}
+
+// flagString represents a string flag.
+type flagString struct{}
+
+// Parse returns the value as-is.
+//
+// Parse implements the FlagKindE interface.
+func (f flagString) Parse(value string) (interface{}, error) {
+	return value, nil
+}
+
+// HasArg implements the FlagKindE interface.
+func (f flagString) HasArg() bool {
+	return true
+}
+
+// flagBool represents a boolean flag.
+type flagBool struct{}
+
+// Parse returns "true" if the value is
+// 1, t, T, true, TRUE, True, y, Y, yes, YES, Yes.
+//
+// Parse implements the FlagKindE interface.
+func (f flagBool) Parse(value string) (interface{}, error) {
+	switch value {
+	case "1", "t", "T", "true", "TRUE", "True", "y", "Y", "yes", "YES", "Yes":
+		return true, nil
+	}
+	return false, nil
+}
+
+// HasArg implements the FlagKindE interface.
+func (f flagBool) HasArg() bool {
+	return false
+}
+
+// flagInt represents an int flag.
+type flagInt struct{}
+
+// Parse returns the value as parsed by strconv.ParseInt.
+// The base is implied by the prefix as in Go syntax.
+//
+// Parse implements the FlagKindE interface.
+func (f flagInt) Parse(value string) (interface{}, error) {
+	v, err := strconv.ParseInt(value, 0, strconv.IntSize)
+	if err != nil {
+		return nil, numError(err)
+	}
+	return int(v), nil
+}
+
+// HasArg implements the FlagKindE interface.
+func (f flagInt) HasArg() bool {
+	return true
+}
+
+// flagInt64 represents an int64 flag.
+type flagInt64 struct{}
+
+// Parse returns the value as parsed by strconv.ParseInt.
+// The base is implied by the prefix as in Go syntax.
+//
+// Parse implements the FlagKindE interface.
+func (f flagInt64) Parse(value string) (interface{}, error) {
+	v, err := strconv.ParseInt(value, 0, 64)
+	if err != nil {
+		return nil, numError(err)
+	}
+	return v, nil
+}
+
+// HasArg implements the FlagKindE interface.
+func (f flagInt64) HasArg() bool {
+	return true
+}
+
+// flagUint represents a uint flag.
+type flagUint struct{}
+
+// Parse returns the value as parsed by strconv.ParseUint.
+// The base is implied by the prefix as in Go syntax.
+//
+// Parse implements the FlagKindE interface.
+func (f flagUint) Parse(value string) (interface{}, error) {
+	v, err := strconv.ParseUint(value, 0, strconv.IntSize)
+	if err != nil {
+		return nil, numError(err)
+	}
+	return uint(v), nil
+}
+
+// HasArg implements the FlagKindE interface.
+func (f flagUint) HasArg() bool {
+	return true
+}
+
+// flagFloat64 represents a float64 flag.
+type flagFloat64 struct{}
+
+// Parse returns the value as parsed by strconv.ParseFloat.
+//
+// Parse implements the FlagKindE interface.
+func (f flagFloat64) Parse(value string) (interface{}, error) {
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, numError(err)
+	}
+	return v, nil
+}
+
+// HasArg implements the FlagKindE interface.
+func (f flagFloat64) HasArg() bool {
+	return true
+}
+
+// flagDuration represents a time.Duration flag.
+type flagDuration struct{}
+
+// Parse returns the value as parsed by time.ParseDuration.
+//
+// Parse implements the FlagKindE interface.
+func (f flagDuration) Parse(value string) (interface{}, error) {
+	return time.ParseDuration(value)
+}
+
+// HasArg implements the FlagKindE interface.
+func (f flagDuration) HasArg() bool {
+	return true
+}
+
+// flagNumber represents a sized integer or float flag.
+type flagNumber struct {
+	t reflect.Type
+}
+
+// Parse returns the value as parsed by strconv for the bit
+// size of the flag type. Integer bases are implied by the
+// prefix as in Go syntax.
+//
+// Parse implements the FlagKindE interface.
+func (f flagNumber) Parse(value string) (interface{}, error) {
+	v := reflect.New(f.t).Elem()
+	bits := f.t.Bits()
+	switch f.t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 0, bits)
+		if err != nil {
+			return nil, numError(err)
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 0, bits)
+		if err != nil {
+			return nil, numError(err)
+		}
+		v.SetUint(n)
+	default:
+		n, err := strconv.ParseFloat(value, bits)
+		if err != nil {
+			return nil, numError(err)
+		}
+		v.SetFloat(n)
+	}
+	return v.Interface(), nil
+}
+
+// HasArg implements the FlagKindE interface.
+func (f flagNumber) HasArg() bool {
+	return true
+}
+
+// flagText represents a flag of a type implementing
+// the encoding.TextUnmarshaler interface.
+type flagText struct {
+	t reflect.Type
+}
+
+// Parse returns a new value unmarshaled from the text.
+//
+// Parse implements the FlagKindE interface.
+func (f flagText) Parse(value string) (interface{}, error) {
+	v := reflect.New(f.t)
+	err := v.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	if err != nil {
+		return nil, err
+	}
+	return v.Elem().Interface(), nil
+}
+
+// HasArg implements the FlagKindE interface.
+func (f flagText) HasArg() bool {
+	return true
+}
+
+// flagValue represents a flag of a type implementing
+// the flag.Value interface.
+type flagValue struct {
+	v    flag.Value
+	elem reflect.Value
+}
+
+// valueKind returns a flag kind for v which must implement flag.Value.
+func valueKind(v reflect.Value) FlagKindE {
+	return newFlagValue(v.Interface().(flag.Value))
+}
+
+// newFlagValue returns a flag kind that sets v.
+func newFlagValue(v flag.Value) flagValue {
+	f := flagValue{v: v}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		f.elem = rv.Elem()
+	}
+	return f
+}
+
+// Parse sets the value by calling its Set method and
+// returns the value that it points to, if any.
+//
+// Parse implements the FlagKindE interface.
+func (f flagValue) Parse(value string) (interface{}, error) {
+	err := f.v.Set(value)
+	if err != nil {
+		return nil, err
+	}
+	if !f.elem.IsValid() {
+		return nil, nil
+	}
+	return f.elem.Interface(), nil
+}
+
+// HasArg returns false if the value is a boolean flag as
+// indicated by an IsBoolFlag method returning true.
+//
+// HasArg implements the FlagKindE interface.
+func (f flagValue) HasArg() bool {
+	b, ok := f.v.(interface{ IsBoolFlag() bool })
+	return !ok || !b.IsBoolFlag()
+}
+
+// flagStrings represents a []string flag.
+type flagStrings struct{}
+
+// Parse returns the value as a single element slice.
+//
+// Parse implements the FlagKindE interface.
+func (f flagStrings) Parse(value string) (interface{}, error) {
+	return []string{value}, nil
+}
+
+// HasArg implements the FlagKindE interface.
+func (f flagStrings) HasArg() bool {
+	return true
+}
+
+// append returns the current slice with value appended.
+func (f flagStrings) append(current interface{}, value string) (interface{}, error) {
+	v := reflect.Append(reflect.ValueOf(current), reflect.ValueOf(value))
+	return v.Interface(), nil
+}
+
+// flagStringMap represents a map[string]string flag.
+type flagStringMap struct{}
+
+// Parse returns the key=value pair as a single entry map.
+//
+// Parse implements the FlagKindE interface.
+func (f flagStringMap) Parse(value string) (interface{}, error) {
+	k, v, err := f.split(value)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{k: v}, nil
+}
+
+// HasArg implements the FlagKindE interface.
+func (f flagStringMap) HasArg() bool {
+	return true
+}
+
+// append returns the current map with the key=value pair added.
+func (f flagStringMap) append(current interface{}, value string) (interface{}, error) {
+	k, v, err := f.split(value)
+	if err != nil {
+		return nil, err
+	}
+	m := reflect.ValueOf(current)
+	if m.IsNil() {
+		m = reflect.MakeMap(m.Type())
+	}
+	m.SetMapIndex(reflect.ValueOf(k), reflect.ValueOf(v))
+	return m.Interface(), nil
+}
+
+// split returns the key and value of a key=value pair.
+func (f flagStringMap) split(value string) (string, string, error) {
+	i := strings.Index(value, "=")
+	if i < 1 {
+		return "", "", errors.New("expected key=value")
+	}
+	return value[:i], value[i+1:], nil
+}
+
+// numError returns the underlying error of a strconv.NumError
+// so that the error message does not repeat the value.
+func numError(err error) error {
+	var nerr *strconv.NumError
+	if errors.As(err, &nerr) {
+		return nerr.Err
+	}
+	return err
+}
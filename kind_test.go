@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestKinds(t *testing.T) {
+	var tests = []struct {
+		kind  FlagKindE
+		value string
+		want  interface{}
+	}{
+		{flagString{}, "string", "string"},
+		{flagBool{}, "yes", true},
+		{flagBool{}, "no", false},
+		{flagInt{}, "-42", -42},
+		{flagInt{}, "0x10", 16},
+		{flagInt64{}, "9000000000", int64(9000000000)},
+		{flagUint{}, "42", uint(42)},
+		{flagFloat64{}, "1.5", 1.5},
+		{flagDuration{}, "1m30s", 90 * time.Second},
+	}
+	for i, tt := range tests {
+		have, err := tt.kind.Parse(tt.value)
+		if err != nil {
+			t.Fatalf("%d. %T.Parse(%q) unexpected error: %v", i, tt.kind, tt.value, err)
+		}
+		if !reflect.DeepEqual(have, tt.want) {
+			t.Fatalf("%d. %T.Parse(%q)\nhave %#v\nwant %#v", i, tt.kind, tt.value, have, tt.want)
+		}
+	}
+}
+
+func TestKindsInvalid(t *testing.T) {
+	var tests = []struct {
+		kind  FlagKindE
+		value string
+	}{
+		{flagInt{}, "abc"},
+		{flagInt{}, "1.5"},
+		{flagInt64{}, ""},
+		{flagUint{}, "-1"},
+		{flagFloat64{}, "abc"},
+		{flagDuration{}, "10"},
+	}
+	for i, tt := range tests {
+		_, err := tt.kind.Parse(tt.value)
+		if err == nil {
+			t.Fatalf("%d. %T.Parse(%q) should error", i, tt.kind, tt.value)
+		}
+	}
+}
+
+func TestParseInvalidValue(t *testing.T) {
+	var retries int
+	flags := []*Flag{NewFlag("retries", &retries, Int(), DefaultValue("3"))}
+	_, err := Parse([]string{"--retries=abc"}, flags)
+	want := ErrInvalidFlagValue{Flag: "retries", Value: "abc", Err: strconv.ErrSyntax}
+	if !reflect.DeepEqual(err, want) {
+		t.Fatalf("error\nhave %v\nwant %v", err, want)
+	}
+	if !errors.Is(err, strconv.ErrSyntax) {
+		t.Fatalf("error should wrap the parse error")
+	}
+	if retries != 3 {
+		t.Fatalf("retries\nhave %d\nwant %d", retries, 3)
+	}
+}
+
+type testKindWrongType struct{}
+
+func (k testKindWrongType) Parse(value string) interface{} {
+	return 42
+}
+
+func (k testKindWrongType) HasArg() bool {
+	return true
+}
+
+func TestFlagSetWrongType(t *testing.T) {
+	var flag string
+	f := NewFlag("flag", &flag, Kind(testKindWrongType{}))
+	err := f.Set("value")
+	var verr ErrInvalidFlagValue
+	if !errors.As(err, &verr) {
+		t.Fatalf("error\nhave %v\nwant %T", err, verr)
+	}
+	if f.IsSet() {
+		t.Fatal("should not be set")
+	}
+}
+
+func TestParseEnvInvalidValue(t *testing.T) {
+	const env = "TEST_PARSE_ENV_INVALID_VALUE"
+	t.Setenv(env, "abc")
+	var retries int
+	flags := []*Flag{NewFlag("retries", &retries, Int(), EnvironmentKey(env))}
+	_, err := Parse(nil, flags)
+	want := ErrInvalidFlagValue{Flag: "retries", Value: "abc", Err: strconv.ErrSyntax}
+	if !reflect.DeepEqual(err, want) {
+		t.Fatalf("error\nhave %v\nwant %v", err, want)
+	}
+}
+
+func TestRunInvalidValue(t *testing.T) {
+	var have error
+	var retries int
+	flags := []*Flag{NewFlag("retries", &retries, Int())}
+	app := New("appname", newTestUsage(t), nil, Resolver(func(err error) { have = err }))
+	app.Add("test", testCommand, flags)
+	err := app.Run([]string{"appname", "test", "--retries", "abc"})
+	var verr ErrInvalidFlagValue
+	if !errors.As(err, &verr) {
+		t.Fatalf("error\nhave %v\nwant %T", err, verr)
+	}
+	if have != err {
+		t.Fatalf("should resolve error\nhave %v\nwant %v", have, err)
+	}
+}
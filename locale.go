@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Catalog maps the format strings of built in messages to translated
+// format strings. The translated format must accept the same verbs in
+// the same order. Messages missing from the catalog are not translated.
+//
+//	Catalog{
+//		"Unknown command '%s'.": "Commande inconnue « %s ».",
+//	}
+type Catalog map[string]string
+
+// sprintf formats the translation of format with args.
+func (cat Catalog) sprintf(format string, args ...interface{}) string {
+	t, ok := cat[format]
+	if ok && t != "" {
+		format = t
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// localized represents an error with a translatable message.
+type localized interface {
+	error
+	message(cat Catalog) string
+}
+
+// LocaleFromEnv returns the locale for messages from the LC_ALL,
+// LC_MESSAGES and LANG environment variables, in that order, as
+// found by lookup. The character encoding and modifier are removed,
+// such as "fr_CA" for "fr_CA.UTF-8". An empty string is returned
+// for the "C" and "POSIX" locales.
+func LocaleFromEnv(lookup func(key string) (string, bool)) string {
+	for _, key := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		value, ok := lookup(key)
+		if !ok || value == "" {
+			continue
+		}
+		i := strings.IndexAny(value, ".@")
+		if i != -1 {
+			value = value[:i]
+		}
+		if value == "C" || value == "POSIX" {
+			return ""
+		}
+		return value
+	}
+	return ""
+}
+
+// localeChain returns the locale fallback chain from the most to
+// the least specific locale, such as "fr_CA" followed by "fr".
+func localeChain(locale string) []string {
+	if locale == "" {
+		return nil
+	}
+	chain := []string{locale}
+	i := strings.IndexAny(locale, "_-")
+	if i > 0 {
+		chain = append(chain, locale[:i])
+	}
+	return chain
+}
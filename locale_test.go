@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLocaleFromEnv(t *testing.T) {
+	var tests = []struct {
+		env  map[string]string
+		want string
+	}{
+		{map[string]string{}, ""},
+		{map[string]string{"LANG": "fr_CA.UTF-8"}, "fr_CA"},
+		{map[string]string{"LANG": "fr_CA", "LC_MESSAGES": "de_DE@euro"}, "de_DE"},
+		{map[string]string{"LANG": "fr_CA", "LC_MESSAGES": "de", "LC_ALL": "es"}, "es"},
+		{map[string]string{"LANG": "C.UTF-8"}, ""},
+		{map[string]string{"LC_ALL": "", "LANG": "fr"}, "fr"},
+	}
+	for _, tt := range tests {
+		have := LocaleFromEnv(testEnviron(tt.env))
+		if have != tt.want {
+			t.Errorf("LocaleFromEnv(%v)\nhave '%s'\nwant '%s'", tt.env, have, tt.want)
+		}
+	}
+}
+
+func TestUsageLocale(t *testing.T) {
+	fsys := fstest.MapFS{
+		"cli/a.md":       {Data: []byte("a")},
+		"cli/b.md":       {Data: []byte("b")},
+		"cli/c.md":       {Data: []byte("c")},
+		"fr/cli/b.md":    {Data: []byte("fr b")},
+		"fr/cli/c.md":    {Data: []byte("fr c")},
+		"fr_CA/cli/c.md": {Data: []byte("fr_CA c")},
+	}
+	var tests = []struct {
+		locale string
+		name   string
+		want   string
+	}{
+		{"fr_CA", "cli/a", "a"},
+		{"fr_CA", "cli/b", "fr b"},
+		{"fr_CA", "cli/c", "fr_CA c"},
+		{"fr", "cli/c", "fr c"},
+		{"", "cli/c", "c"},
+	}
+	for _, tt := range tests {
+		usage := NewUsageFS(fsys, UsageLocale(tt.locale))
+		b, err := fs.ReadFile(usage, tt.name)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		have := string(b)
+		if have != tt.want {
+			t.Errorf("locale '%s' topic '%s'\nhave '%s'\nwant '%s'", tt.locale, tt.name, have, tt.want)
+		}
+	}
+}
+
+func TestMessages(t *testing.T) {
+	catalogs := map[string]Catalog{
+		"fr": {
+			"Unknown command '%s'.":            "Commande inconnue « %s ».",
+			"Run '%s' for usage information.":  "Exécutez « %s » pour l'aide.",
+			"Flags %s are mutually exclusive.": "Les options %s sont incompatibles.",
+			"%s and %s":                        "%s et %s",
+		},
+		"fr_CA": {
+			"Run '%s' for usage information.": "Lancez « %s » pour l'aide.",
+		},
+	}
+	var stderr bytes.Buffer
+	var a, b bool
+	fa := NewFlag("a", &a)
+	fb := NewFlag("b", &b)
+	opts := []Option{Messages("fr_CA", catalogs), Stdout(io.Discard), Stderr(&stderr)}
+	app := New("appname", newTestUsage(t), nil, opts...)
+	app.Add("test", testCommand, []*Flag{fa, fb}, MutuallyExclusive(fa, fb))
+	app.Run([]string{"appname", "unknown"})
+	want := "Commande inconnue « unknown ».\nLancez « appname help » pour l'aide.\n"
+	if stderr.String() != want {
+		t.Fatalf("command not found\nhave '%s'\nwant '%s'", stderr.String(), want)
+	}
+	stderr.Reset()
+	app.Run([]string{"appname", "test", "-a", "-b"})
+	want = "Les options 'a' et 'b' sont incompatibles.\n"
+	if stderr.String() != want {
+		t.Fatalf("resolver\nhave '%s'\nwant '%s'", stderr.String(), want)
+	}
+}
+
+func TestMessagesWrapped(t *testing.T) {
+	catalogs := map[string]Catalog{
+		"fr": {
+			"Flag '%s' is undefined.":         "Option « %s » non définie.",
+			"Duplicate flag '%s'.":            "Option « %s » en double.",
+			"Path to the configuration file.": "Chemin du fichier de configuration.",
+		},
+	}
+	var stderr bytes.Buffer
+	opts := []Option{Messages("fr", catalogs), ConfigFile("config"), Stdout(io.Discard), Stderr(&stderr)}
+	app := New("appname", newTestUsage(t), nil, opts...)
+	app.Add("wrap", func(args []string) error {
+		return fmt.Errorf("wrap: %w", ExitError{Code: 3, Err: ErrUndefinedFlag("x")})
+	}, nil)
+	var a, b bool
+	app.Add("dup", testCommand, []*Flag{NewFlag("a", &a), NewFlag("a", &b)})
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"appname", "wrap"}, "wrap: Option « x » non définie.\n"},
+		{[]string{"appname", "dup"}, "Option « a » en double.\n"},
+	}
+	for _, tt := range tests {
+		stderr.Reset()
+		app.Run(tt.args)
+		if stderr.String() != tt.want {
+			t.Fatalf("%v\nhave '%s'\nwant '%s'", tt.args, stderr.String(), tt.want)
+		}
+	}
+	var usage strings.Builder
+	app.writeFlags(&usage, app.flags)
+	if !strings.Contains(usage.String(), "Chemin du fichier de configuration.") {
+		t.Fatalf("config flag usage should be translated\n%s", usage.String())
+	}
+}
@@ -0,0 +1,318 @@
+package cli
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ANSI escape sequences used by the markdown renderer.
+const (
+	ansiBold      = "\x1b[1m"
+	ansiNoBold    = "\x1b[22m"
+	ansiItalic    = "\x1b[3m"
+	ansiNoItalic  = "\x1b[23m"
+	ansiUnderline = "\x1b[4m"
+	ansiNoUnder   = "\x1b[24m"
+	ansiCyan      = "\x1b[36m"
+	ansiNoColor   = "\x1b[39m"
+)
+
+var (
+	ansiPattern    = regexp.MustCompile("\x1b\\[[0-9;]*m")
+	headingPattern = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	listPattern    = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])\s+(.*)$`)
+)
+
+// markdown represents a terminal renderer for a subset of CommonMark.
+// Headings, emphasis, code spans, code blocks, lists and links are
+// supported. Paragraphs and list items are wrapped to width columns.
+type markdown struct {
+	width int
+	ansi  bool
+}
+
+// render returns the rendered markdown source.
+func (m *markdown) render(src []byte) []byte {
+	lines := strings.Split(strings.ReplaceAll(string(src), "\r\n", "\n"), "\n")
+	var blocks, para []string
+	var items []markdownItem
+	flush := func() {
+		if len(para) > 0 {
+			blocks = append(blocks, m.wrap(m.inline(strings.Join(para, " ")), "", ""))
+			para = nil
+		}
+		if len(items) > 0 {
+			list := make([]string, len(items))
+			for i, item := range items {
+				rest := item.indent + strings.Repeat(" ", utf8.RuneCountInString(item.marker)+1)
+				list[i] = m.wrap(m.inline(item.text), item.indent+item.marker+" ", rest)
+			}
+			blocks = append(blocks, strings.Join(list, "\n"))
+			items = nil
+		}
+	}
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			if len(items) > 0 && nextListItem(lines[i+1:], items[len(items)-1].ordered) {
+				continue
+			}
+			flush()
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			flush()
+			fence := trimmed[:3]
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), fence); i++ {
+				code = append(code, lines[i])
+			}
+			blocks = append(blocks, m.code(code))
+			continue
+		}
+		if len(para) == 0 && len(items) == 0 && isCodeIndent(line) {
+			var code []string
+			for ; i < len(lines) && (isCodeIndent(lines[i]) || strings.TrimSpace(lines[i]) == ""); i++ {
+				code = append(code, strings.TrimPrefix(strings.TrimPrefix(lines[i], "\t"), "    "))
+			}
+			i--
+			for len(code) > 0 && strings.TrimSpace(code[len(code)-1]) == "" {
+				code = code[:len(code)-1]
+			}
+			blocks = append(blocks, m.code(code))
+			continue
+		}
+		match := headingPattern.FindStringSubmatch(trimmed)
+		if match != nil {
+			flush()
+			blocks = append(blocks, m.heading(len(match[1]), match[2]))
+			continue
+		}
+		match = listPattern.FindStringSubmatch(line)
+		if match != nil {
+			if len(para) > 0 {
+				flush()
+			}
+			depth := len(strings.ReplaceAll(match[1], "\t", "    ")) / 2
+			marker := match[2]
+			ordered := unicode.IsDigit(rune(marker[0]))
+			if m.ansi && !ordered {
+				marker = "•"
+			}
+			items = append(items, markdownItem{
+				indent:  strings.Repeat("  ", depth),
+				marker:  marker,
+				text:    match[3],
+				ordered: ordered,
+			})
+			continue
+		}
+		if len(items) > 0 {
+			items[len(items)-1].text += " " + trimmed
+			continue
+		}
+		para = append(para, trimmed)
+	}
+	flush()
+	if len(blocks) == 0 {
+		return nil
+	}
+	return []byte(strings.Join(blocks, "\n\n") + "\n")
+}
+
+// markdownItem represents a list item.
+type markdownItem struct {
+	indent  string
+	marker  string
+	text    string
+	ordered bool
+}
+
+// heading returns the rendered heading text.
+func (m *markdown) heading(level int, text string) string {
+	text = m.inline(text)
+	if !m.ansi {
+		return text
+	}
+	if level == 1 {
+		return ansiBold + ansiUnderline + text + ansiNoUnder + ansiNoBold
+	}
+	return ansiBold + text + ansiNoBold
+}
+
+// code returns the rendered code block lines. Code
+// blocks are indented and are not wrapped.
+func (m *markdown) code(lines []string) string {
+	for i, line := range lines {
+		if m.ansi {
+			line = ansiCyan + line + ansiNoColor
+		}
+		lines[i] = strings.TrimRight("    "+line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// inline returns s with inline markup rendered. Backslash escapes,
+// code spans, strong and regular emphasis and links are supported.
+func (m *markdown) inline(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch c {
+		case '\\':
+			if i+1 < len(s) && isEscapable(s[i+1]) {
+				b.WriteByte(s[i+1])
+				i += 2
+				continue
+			}
+		case '`':
+			n := 1
+			for i+n < len(s) && s[i+n] == '`' {
+				n++
+			}
+			end := strings.Index(s[i+n:], s[i:i+n])
+			if end >= 0 {
+				code := s[i+n : i+n+end]
+				if len(code) > 2 && code[0] == ' ' && code[len(code)-1] == ' ' {
+					code = code[1 : len(code)-1]
+				}
+				b.WriteString(m.style(code, ansiCyan, ansiNoColor))
+				i += n + end + n
+				continue
+			}
+		case '*', '_':
+			delim := s[i : i+1]
+			if i+1 < len(s) && s[i+1] == c {
+				delim = s[i : i+2]
+			}
+			end := emphasis(s, i, delim)
+			if end >= 0 {
+				inner := m.inline(s[i+len(delim) : end])
+				if len(delim) == 2 {
+					b.WriteString(m.style(inner, ansiBold, ansiNoBold))
+				} else {
+					b.WriteString(m.style(inner, ansiItalic, ansiNoItalic))
+				}
+				i = end + len(delim)
+				continue
+			}
+		case '[':
+			label := strings.IndexAny(s[i+1:], "[]") + 1
+			if label > 0 && s[i+label] == ']' && strings.HasPrefix(s[i+label+1:], "(") {
+				end := strings.IndexByte(s[i+label:], ')')
+				if end >= 0 {
+					text := m.inline(s[i+1 : i+label])
+					url := s[i+label+2 : i+label+end]
+					b.WriteString(m.style(text, ansiUnderline, ansiNoUnder))
+					if url != text {
+						b.WriteString(" (" + url + ")")
+					}
+					i += label + end + 1
+					continue
+				}
+			}
+		}
+		b.WriteByte(c)
+		i++
+	}
+	return b.String()
+}
+
+// style returns s wrapped in the ANSI sequences if enabled.
+func (m *markdown) style(s, start, end string) string {
+	if !m.ansi {
+		return s
+	}
+	return start + s + end
+}
+
+// wrap returns text wrapped to the renderer width. The first line
+// is prefixed with first and the following lines with rest.
+func (m *markdown) wrap(text, first, rest string) string {
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return strings.TrimRight(first, " ")
+	}
+	var b strings.Builder
+	b.WriteString(first)
+	n := visibleLen(first)
+	for i, word := range words {
+		w := visibleLen(word)
+		if i > 0 {
+			if m.width > 0 && n+1+w > m.width {
+				b.WriteString("\n" + rest)
+				n = visibleLen(rest)
+			} else {
+				b.WriteByte(' ')
+				n++
+			}
+		}
+		b.WriteString(word)
+		n += w
+	}
+	return b.String()
+}
+
+// emphasis returns the index of the delimiter closing the emphasis
+// opened at index i or -1 if the emphasis is not closed. Underscores
+// do not open or close emphasis within words.
+func emphasis(s string, i int, delim string) int {
+	start := i + len(delim)
+	if start >= len(s) || s[start] == ' ' {
+		return -1
+	}
+	if delim[0] == '_' && i > 0 && isWordByte(s[i-1]) {
+		return -1
+	}
+	for j := start + 1; j+len(delim) <= len(s); j++ {
+		if s[j:j+len(delim)] != delim || s[j-1] == ' ' {
+			continue
+		}
+		if len(delim) == 1 && j+1 < len(s) && s[j+1] == delim[0] {
+			j++
+			continue
+		}
+		if delim[0] == '_' && j+len(delim) < len(s) && isWordByte(s[j+len(delim)]) {
+			continue
+		}
+		return j
+	}
+	return -1
+}
+
+// nextListItem returns true if the next non-blank line is a
+// list item of the same kind, ordered or unordered.
+func nextListItem(lines []string, ordered bool) bool {
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		match := listPattern.FindStringSubmatch(line)
+		return match != nil && unicode.IsDigit(rune(match[2][0])) == ordered
+	}
+	return false
+}
+
+// isCodeIndent returns true if the line is indented as a code block.
+func isCodeIndent(line string) bool {
+	return strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t")
+}
+
+// isEscapable returns true if b is an ASCII punctuation
+// character that may be escaped with a backslash.
+func isEscapable(b byte) bool {
+	return b < utf8.RuneSelf && (unicode.IsPunct(rune(b)) || unicode.IsSymbol(rune(b)))
+}
+
+// isWordByte returns true if b is an ASCII letter or digit.
+func isWordByte(b byte) bool {
+	return b < utf8.RuneSelf && (unicode.IsLetter(rune(b)) || unicode.IsDigit(rune(b)))
+}
+
+// visibleLen returns the number of visible characters in s.
+func visibleLen(s string) int {
+	return utf8.RuneCountInString(ansiPattern.ReplaceAllString(s, ""))
+}
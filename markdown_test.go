@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"testing/fstest"
+)
+
+const testMarkdown = "# appname\n" +
+	"\n" +
+	"Manage **deployments** of the *current* project. See\n" +
+	"[the docs](https://example.com) for `snake_case` keys.\n" +
+	"\n" +
+	"## Commands\n" +
+	"\n" +
+	"- deploy: ship the current build to the configured environment\n" +
+	"- rollback\n" +
+	"  - to the previous release\n" +
+	"\n" +
+	"1. first\n" +
+	"2. second\n" +
+	"\n" +
+	"```\n" +
+	"appname deploy --force\n" +
+	"```\n" +
+	"\n" +
+	"    appname rollback\n" +
+	"\n" +
+	"Escaped \\*stars\\* and snake_case_words stay.\n" +
+	"\n" +
+	"Use [options] then see [docs](http://x).\n"
+
+func TestMarkdownPlain(t *testing.T) {
+	m := &markdown{width: 40}
+	have := string(m.render([]byte(testMarkdown)))
+	want := `appname
+
+Manage deployments of the current
+project. See the docs
+(https://example.com) for snake_case
+keys.
+
+Commands
+
+- deploy: ship the current build to the
+  configured environment
+- rollback
+  - to the previous release
+
+1. first
+2. second
+
+    appname deploy --force
+
+    appname rollback
+
+Escaped *stars* and snake_case_words
+stay.
+
+Use [options] then see docs (http://x).
+`
+	if have != want {
+		t.Fatalf("render\nhave\n%s\nwant\n%s", have, want)
+	}
+}
+
+func TestMarkdownANSI(t *testing.T) {
+	m := &markdown{ansi: true}
+	var tests = []struct {
+		in   string
+		want string
+	}{
+		{"# Title", "\x1b[1m\x1b[4mTitle\x1b[24m\x1b[22m\n"},
+		{"## Title", "\x1b[1mTitle\x1b[22m\n"},
+		{"**bold** _em_ `code`", "\x1b[1mbold\x1b[22m \x1b[3mem\x1b[23m \x1b[36mcode\x1b[39m\n"},
+		{"[docs](https://example.com)", "\x1b[4mdocs\x1b[24m (https://example.com)\n"},
+		{"- item", "• item\n"},
+	}
+	for _, tt := range tests {
+		have := string(m.render([]byte(tt.in)))
+		if have != tt.want {
+			t.Errorf("render %q\nhave %q\nwant %q", tt.in, have, tt.want)
+		}
+	}
+}
+
+func TestMarkdownWrapANSI(t *testing.T) {
+	m := &markdown{width: 10, ansi: true}
+	have := string(m.render([]byte("**aaaa** bbbb cccc")))
+	want := "\x1b[1maaaa\x1b[22m bbbb\ncccc\n"
+	if have != want {
+		t.Fatalf("escape sequences should not count toward width\nhave %q\nwant %q", have, want)
+	}
+}
+
+func TestMarkdownUsage(t *testing.T) {
+	usage := NewUsageFS(fstest.MapFS{
+		"README.md": {Data: []byte("# appname\n\nRun **appname**.\n")},
+	})
+	var buf bytes.Buffer
+	app := New("appname", usage, nil, MarkdownUsage(), Stdout(&buf), Stderr(io.Discard))
+	err := app.Run([]string{"appname", "help"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "appname\n\nRun appname.\n"
+	if buf.String() != want {
+		t.Fatalf("usage\nhave %q\nwant %q", buf.String(), want)
+	}
+}
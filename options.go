@@ -12,6 +12,13 @@ func Help(handler Handler) Option {
 	}
 }
 
+// About sets the application description for generated help output.
+func About(text string) Option {
+	return func(c *CLI) {
+		c.description = text
+	}
+}
+
 // Version enables the application version handler.
 func Version(version string) Option {
 	return func(c *CLI) {
@@ -26,6 +33,114 @@ func Prefix(prefix string) Option {
 	}
 }
 
+// Environ sets the function used to look up environment variables.
+// Defaults to os.LookupEnv. Flag values and the configuration file
+// location are resolved through lookup, so tests may provide a map
+// and applications may expose a filtered view of the environment.
+func Environ(lookup func(key string) (string, bool)) Option {
+	return func(c *CLI) {
+		c.lookupEnv = lookup
+	}
+}
+
+// ExitFunc sets the function called by Main and on a second
+// interrupt signal to exit the application. Defaults to os.Exit.
+func ExitFunc(exit func(code int)) Option {
+	return func(c *CLI) {
+		c.exit = exit
+	}
+}
+
+// ExitCodeFor sets the exit status used by Main for errors that
+// match target, as reported by errors.Is. Codes are checked in the
+// order registered, before the defaults described by ExitCode.
+func ExitCodeFor(target error, code int) Option {
+	return func(c *CLI) {
+		c.exitCodes = append(c.exitCodes, exitCode{match: matchIs(target), code: code})
+	}
+}
+
+// ExitCodeAs sets the exit status used by Main for errors of the type
+// that target points to, as reported by errors.As. This maps typed
+// errors that have no sentinel value, such as:
+//
+//	ExitCodeAs(new(ErrInvalidConfig), 78)
+//	ExitCodeAs(new(*MyError), 3)
+//
+// Codes are checked in the order registered, along with ExitCodeFor.
+// ExitCodeAs panics if target is not a non-nil pointer to a type that
+// implements error or to an interface type.
+func ExitCodeAs(target interface{}, code int) Option {
+	m := matchAs(target)
+	return func(c *CLI) {
+		c.exitCodes = append(c.exitCodes, exitCode{match: m, code: code})
+	}
+}
+
+// POSIX enables POSIX style flag parsing. A single hyphen introduces
+// a cluster of short flags, such as "-xvf file", and a short flag that
+// requires an argument may attach its value, such as "-ofile". Long
+// flag names must be introduced by a double hyphen.
+func POSIX() Option {
+	return func(c *CLI) {
+		c.posix = true
+	}
+}
+
+// ConfigFile enables the configuration file layer. The name is the
+// file name within the application directory of the XDG configuration
+// directories, such as "config.json" for "~/.config/appname/config.json".
+// The path may be overridden with the built in --config flag or its
+// environment variable. Files with a ".json" extension are parsed as
+// JSON and all other files as INI style key=value lines.
+//
+// Configuration values have the lowest precedence, above only the
+// flag default values. See the README for the full precedence chain.
+func ConfigFile(name string) Option {
+	return func(c *CLI) {
+		c.configName = name
+	}
+}
+
+// Messages sets the catalog used to translate built in messages,
+// including errors, command not found suggestions and generated help.
+// The catalogs are keyed by locale and merged along the fallback
+// chain of locale, so "fr_CA" translations take precedence over
+// "fr" translations. See LocaleFromEnv to find the user's locale.
+func Messages(locale string, catalogs map[string]Catalog) Option {
+	return func(c *CLI) {
+		chain := localeChain(locale)
+		c.catalog = make(Catalog)
+		for i := len(chain) - 1; i >= 0; i-- {
+			for k, v := range catalogs[chain[i]] {
+				c.catalog[k] = v
+			}
+		}
+	}
+}
+
+// Pager pipes the output of the built in help command through the
+// pager in the PAGER environment variable, or "less -FRX" if not set,
+// when stdout is a terminal and the output is taller than it. PAGER
+// is run by the shell, so it may contain quoted arguments. The pager
+// is disabled by the built in --no-pager flag or a non-empty NO_PAGER
+// environment variable. Output that is not written to a terminal is
+// never paged.
+func Pager() Option {
+	return func(c *CLI) {
+		c.pager = true
+	}
+}
+
+// DebugFlags enables the built in --debug-flags flag. When set, the
+// effective value and source of every flag parsed for the command are
+// written to stderr before the command is dispatched.
+func DebugFlags() Option {
+	return func(c *CLI) {
+		c.debugFlags = true
+	}
+}
+
 // Scope sets the help topic scope for registered commands.
 // See Usage documentation for more information.
 func Scope(scope string) Option {
@@ -34,6 +149,28 @@ func Scope(scope string) Option {
 	}
 }
 
+// TemplateUsage executes usage files as text/template templates
+// with UsageData as the data. The env, default and alias functions
+// return the environment variable key, default value and short flag
+// of a flag, so usage files can refer to live flag definitions:
+//
+//	Listens on {{.Flag "port" | default}}, or ${{.Flag "port" | env}}.
+func TemplateUsage() Option {
+	return func(c *CLI) {
+		c.templateUsage = true
+	}
+}
+
+// MarkdownUsage renders usage files as markdown. Headings, emphasis,
+// code, lists and links are styled with ANSI escape sequences when
+// written to a terminal and written as plain text otherwise. Paragraphs
+// are wrapped to the terminal width or the COLUMNS environment variable.
+func MarkdownUsage() Option {
+	return func(c *CLI) {
+		c.markdownUsage = true
+	}
+}
+
 // Default sets the handler to execute when no command is given.
 func Default(handler Handler) Option {
 	return func(c *CLI) {
@@ -84,6 +221,19 @@ func UsageExt(ext string) UsageOption {
 	}
 }
 
+// UsageLocale sets the locale used to resolve usage files. Files are
+// looked up in the directory of the locale, then the directory of its
+// language and then the root of the usage FS. For example, the "foo"
+// topic with the "fr_CA" locale and the "cli" scope is looked up as
+// "fr_CA/cli/foo.md", "fr/cli/foo.md" and then "cli/foo.md".
+//
+//	NewUsageFS(fsys, UsageLocale(LocaleFromEnv(os.LookupEnv)))
+func UsageLocale(locale string) UsageOption {
+	return func(u *UsageFS) {
+		u.locales = localeChain(locale)
+	}
+}
+
 // UsageIndex sets the usage index file.
 // The configured usage extension will be appended
 // to the index name. Defaults to "README".
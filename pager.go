@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+)
+
+// noPagerFlagName is the name of the built in flag that disables the pager.
+const noPagerFlagName = "no-pager"
+
+// defaultPager is the pager command used if PAGER is not set.
+const defaultPager = "less -FRX"
+
+// pagerBuffer represents output buffered for the pager.
+type pagerBuffer struct {
+	bytes.Buffer
+	file *os.File
+}
+
+// page calls write with a writer for stdout. If the pager is enabled
+// and stdout is a terminal, the output is buffered and piped through
+// the pager when it is taller than the terminal. The pager is disabled
+// by the --no-pager flag or a non-empty NO_PAGER environment variable.
+func (c *CLI) page(write func(w io.Writer) error) error {
+	if !c.pager || c.noPager || c.getenv("NO_PAGER") != "" {
+		return write(c.stdout)
+	}
+	f, ok := terminalFile(c.stdout)
+	if !ok {
+		return write(c.stdout)
+	}
+	buf := &pagerBuffer{file: f}
+	err := write(buf)
+	if buf.Len() == 0 {
+		return err
+	}
+	_, rows, ok := terminalSize(f)
+	if !ok || bytes.Count(buf.Bytes(), []byte("\n")) < rows {
+		_, werr := f.Write(buf.Bytes())
+		if err == nil {
+			err = werr
+		}
+		return err
+	}
+	perr := c.runPager(f, buf.Bytes())
+	if err == nil {
+		err = perr
+	}
+	return err
+}
+
+// runPager writes b to f through the command in the PAGER environment
+// variable, or "less -FRX" if not set. The command is run with "sh -c"
+// so that quoting is interpreted as git does. The output is written
+// directly to f if the pager cannot be started.
+func (c *CLI) runPager(f *os.File, b []byte) error {
+	pager := c.getenv("PAGER")
+	if pager == "" {
+		pager = defaultPager
+	}
+	cmd := exec.Command("sh", "-c", pager)
+	cmd.Stdin = bytes.NewReader(b)
+	cmd.Stdout = f
+	cmd.Stderr = c.stderr
+	err := cmd.Start()
+	if err != nil {
+		_, err = f.Write(b)
+		return err
+	}
+	return cmd.Wait()
+}
@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestPagerPassthrough(t *testing.T) {
+	for _, args := range [][]string{
+		{"appname", "help", "test"},
+		{"appname", "--no-pager", "help", "test"},
+	} {
+		var buf bytes.Buffer
+		app := New("appname", newTestUsage(t), nil, Pager(), Stdout(&buf), Stderr(io.Discard))
+		app.Add("test", testCommand, nil)
+		err := app.Run(args)
+		if err != nil {
+			t.Fatalf("help %v\nunexpected error: %v", args, err)
+		}
+		want := "test.md\n"
+		if buf.String() != want {
+			t.Fatalf("help %v\nhave '%s'\nwant '%s'", args, buf.String(), want)
+		}
+	}
+}
+
+func TestRunPager(t *testing.T) {
+	_, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh is not available")
+	}
+	var tests = []struct {
+		pager string
+	}{
+		{"cat"},
+		{`cat "-"`},
+		{"cat '-' -"},
+	}
+	for _, tt := range tests {
+		env := map[string]string{"PAGER": tt.pager}
+		app := New("appname", nil, nil, Environ(testEnviron(env)), Stderr(io.Discard))
+		path := filepath.Join(t.TempDir(), "out")
+		f, err := os.Create(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		err = app.runPager(f, []byte("paged\n"))
+		f.Close()
+		if err != nil {
+			t.Fatalf("pager '%s'\nunexpected error: %v", tt.pager, err)
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(b) != "paged\n" {
+			t.Fatalf("pager '%s'\nhave '%s'\nwant '%s'", tt.pager, b, "paged\n")
+		}
+	}
+}
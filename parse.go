@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// parser represents the flag parser configuration.
+type parser struct {
+	posix        bool
+	interspersed bool
+	lookupEnv    func(key string) (string, bool)
+}
+
+// Parse parses flag definitions from the argument list. Flag parsing stops
+// at the first non-flag argument, including single or double hyphens followed
+// by whitespace or end of input. Values that cannot be parsed by the flag kind,
+// including values from environment variables, return an ErrInvalidFlagValue.
+func Parse(args []string, flags []*Flag) ([]string, error) {
+	return ParseEnv(args, flags, os.LookupEnv)
+}
+
+// ParseEnv parses flag definitions from the argument list as Parse does,
+// looking up environment variables with lookup instead of os.LookupEnv.
+func ParseEnv(args []string, flags []*Flag, lookup func(key string) (string, bool)) ([]string, error) {
+	p := &parser{lookupEnv: lookup}
+	return p.parse(args, flags)
+}
+
+// parse parses flags from args and returns the remaining arguments.
+//
+// Flag names may be given with either single or double hyphens. In
+// POSIX mode, a single hyphen introduces a cluster of short flags and
+// only a double hyphen introduces a long flag name. In interspersed
+// mode, flags may appear anywhere and non-flag arguments are collected
+// until a double hyphen ends flag processing.
+func (p *parser) parse(args []string, flags []*Flag) ([]string, error) {
+	long := make(map[string]*Flag)
+	short := make(map[string]*Flag)
+	for _, f := range flags {
+		long[f.name] = f
+		if f.alias != "" {
+			short[f.alias] = f
+			if !p.posix {
+				long[f.alias] = f
+			}
+		}
+		value, ok := p.lookupEnv(f.envKey)
+		if ok {
+			err := f.load(value)
+			if err != nil {
+				return nil, err
+			}
+			f.count++
+			f.source = Source{Kind: SourceEnv, Name: f.envKey, Value: value}
+		}
+	}
+	var positional []string
+	for len(args) > 0 {
+		arg := args[0]
+		if arg == "--" {
+			break
+		}
+		if arg == "" || arg == "-" || arg[0] != '-' {
+			if !p.interspersed {
+				break
+			}
+			positional = append(positional, arg)
+			args = args[1:]
+			continue
+		}
+		var err error
+		if p.posix && arg[1] != '-' {
+			args, err = p.parseShort(arg[1:], args[1:], short)
+		} else if arg[1] == '-' {
+			args, err = p.parseLong(arg[2:], args[1:], long)
+		} else {
+			args, err = p.parseLong(arg[1:], args[1:], long)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(positional) > 0 {
+		args = append(positional, args...)
+	}
+	return args, nil
+}
+
+// parseLong parses a single named flag with an optional value
+// separated by an equals sign. Flags that require an argument
+// without an equals sign consume the next argument.
+func (p *parser) parseLong(arg string, args []string, flags map[string]*Flag) ([]string, error) {
+	if !unicode.IsLetter(rune(arg[0])) {
+		return nil, ErrFlagSyntax(arg)
+	}
+	key := arg
+	i := strings.Index(arg, "=")
+	if i != -1 {
+		key = arg[:i]
+	}
+	f, ok := flags[key]
+	if !ok {
+		f, ok = flags[strings.TrimPrefix(key, "no-")]
+		if !ok || !f.negatable || !strings.HasPrefix(key, "no-") {
+			return nil, ErrUndefinedFlag(key)
+		}
+		if i != -1 {
+			return nil, ErrFlagSyntax(arg)
+		}
+		return args, f.Set("false")
+	}
+	if i != -1 {
+		return args, f.Set(arg[i+1:])
+	}
+	if !f.kind.HasArg() {
+		return args, f.Set("true")
+	}
+	return p.value(key, f, args)
+}
+
+// parseShort parses a cluster of short flags. The first short flag
+// that requires an argument consumes the remainder of the cluster as
+// its value, or the next argument if it is the last in the cluster.
+func (p *parser) parseShort(cluster string, args []string, flags map[string]*Flag) ([]string, error) {
+	for i := 0; i < len(cluster); {
+		_, size := utf8.DecodeRuneInString(cluster[i:])
+		key := cluster[i : i+size]
+		i += size
+		f, ok := flags[key]
+		if !ok {
+			return nil, ErrUndefinedFlag(key)
+		}
+		if !f.kind.HasArg() {
+			err := f.Set("true")
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if i < len(cluster) {
+			return args, f.Set(cluster[i:])
+		}
+		return p.value(key, f, args)
+	}
+	return args, nil
+}
+
+// value sets f to the next argument and returns the remaining arguments.
+func (p *parser) value(key string, f *Flag, args []string) ([]string, error) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return nil, ErrRequiresArg(key)
+	}
+	err := f.Set(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return args[1:], nil
+}
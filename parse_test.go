@@ -0,0 +1,177 @@
+package cli
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testPOSIX struct {
+	x    bool
+	v    bool
+	file string
+	args []string
+}
+
+func TestParsePOSIX(t *testing.T) {
+	tests := map[string]*testPOSIX{
+		"-xvf file test":             &testPOSIX{x: true, v: true, file: "file"},
+		"-xvffile test":              &testPOSIX{x: true, v: true, file: "file"},
+		"-x -v -f file test":         &testPOSIX{x: true, v: true, file: "file"},
+		"-ffile test arg":            &testPOSIX{file: "file", args: []string{"arg"}},
+		"--file file -x test":        &testPOSIX{x: true, file: "file"},
+		"--file=file --verbose test": &testPOSIX{v: true, file: "file"},
+		"-x test -- -v":              &testPOSIX{x: true, args: []string{"--", "-v"}},
+	}
+	for line, want := range tests {
+		c := &testPOSIX{}
+		flags := []*Flag{
+			NewFlag("extract", &c.x, Bool(), ShortFlag("x")),
+			NewFlag("verbose", &c.v, Bool(), ShortFlag("v")),
+			NewFlag("file", &c.file, ShortFlag("f")),
+		}
+		app := New("appname", newTestUsage(t), flags, POSIX())
+		app.Add("test", func(args []string) error {
+			if len(args) > 0 {
+				c.args = args
+			}
+			return nil
+		}, nil)
+		args := append([]string{"appname"}, strings.Split(line, " ")...)
+		err := app.Run(args)
+		if err != nil {
+			t.Fatalf("unexpected error for '%s': %v", line, err)
+		}
+		if !reflect.DeepEqual(c, want) {
+			t.Fatalf("flags for '%s'\nhave %+v\nwant %+v", line, c, want)
+		}
+	}
+}
+
+func TestParsePOSIXErrors(t *testing.T) {
+	tests := map[string]error{
+		"-xz":      ErrUndefinedFlag("z"),
+		"-extract": ErrUndefinedFlag("e"),
+		"--x":      ErrUndefinedFlag("x"),
+		"-xf":      ErrRequiresArg("f"),
+		"-f -x":    ErrRequiresArg("f"),
+	}
+	for line, want := range tests {
+		c := &testPOSIX{}
+		flags := []*Flag{
+			NewFlag("extract", &c.x, Bool(), ShortFlag("x")),
+			NewFlag("file", &c.file, ShortFlag("f")),
+		}
+		app := New("appname", newTestUsage(t), flags, POSIX(), Resolver(func(error) {}), Stderr(io.Discard))
+		args := append([]string{"appname"}, strings.Split(line, " ")...)
+		err := app.Run(args)
+		if !reflect.DeepEqual(err, want) {
+			t.Fatalf("error for '%s'\nhave %v\nwant %v", line, err, want)
+		}
+	}
+}
+
+func TestParseInterspersed(t *testing.T) {
+	tests := map[string][]string{
+		"service-a --force":      []string{"service-a"},
+		"--force service-a":      []string{"service-a"},
+		"a --name n b --force":   []string{"a", "b"},
+		"a --force -- --name n":  []string{"a", "--", "--name", "n"},
+		"- --force":              []string{"-"},
+		"--force a -- b --force": []string{"a", "--", "b", "--force"},
+		"a b c --force --name=n": []string{"a", "b", "c"},
+	}
+	for line, want := range tests {
+		var have []string
+		var force bool
+		var name string
+		flags := []*Flag{
+			NewFlag("force", &force, Bool()),
+			NewFlag("name", &name),
+		}
+		app := New("appname", newTestUsage(t), nil)
+		app.Add("deploy", func(args []string) error {
+			have = args
+			return nil
+		}, flags, Interspersed())
+		args := append([]string{"appname", "deploy"}, strings.Split(line, " ")...)
+		err := app.Run(args)
+		if err != nil {
+			t.Fatalf("unexpected error for '%s': %v", line, err)
+		}
+		if !force {
+			t.Fatalf("force should be set for '%s'", line)
+		}
+		if !reflect.DeepEqual(have, want) {
+			t.Fatalf("args for '%s'\nhave %v\nwant %v", line, have, want)
+		}
+	}
+}
+
+func TestParseInterspersedProxy(t *testing.T) {
+	var have []string
+	app := New("appname", newTestUsage(t), nil)
+	app.Add("exec", func(args []string) error {
+		have = args
+		return nil
+	}, nil, Interspersed(), Proxy())
+	err := app.Run([]string{"appname", "exec", "a", "--force"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"a", "--force"}
+	if !reflect.DeepEqual(have, want) {
+		t.Fatalf("args\nhave %v\nwant %v", have, want)
+	}
+}
+
+func TestParseNegatable(t *testing.T) {
+	tests := map[string]error{
+		"--no-color":         nil,
+		"-no-color":          nil,
+		"--color --no-color": nil,
+		"--no-color=true":    ErrFlagSyntax("no-color=true"),
+		"--no-verbose":       ErrUndefinedFlag("no-verbose"),
+	}
+	for line, want := range tests {
+		var color, verbose bool
+		f := NewFlag("color", &color, Bool(), Negatable(), DefaultValue("true"))
+		flags := []*Flag{f, NewFlag("verbose", &verbose, Bool())}
+		_, err := Parse(strings.Split(line, " "), flags)
+		if !reflect.DeepEqual(err, want) {
+			t.Fatalf("error for '%s'\nhave %v\nwant %v", line, err, want)
+		}
+		if err != nil {
+			continue
+		}
+		if color || !f.IsSet() {
+			t.Fatalf("color should be set to false for '%s'", line)
+		}
+	}
+}
+
+func TestParsePOSIXNegatable(t *testing.T) {
+	tests := map[string]error{
+		"--no-color test":    nil,
+		"-c --no-color test": nil,
+		"-no-color test":     ErrUndefinedFlag("n"),
+	}
+	for line, want := range tests {
+		var color bool
+		f := NewFlag("color", &color, Bool(), Negatable(), ShortFlag("c"), DefaultValue("true"))
+		app := New("appname", newTestUsage(t), []*Flag{f}, POSIX(), Resolver(func(error) {}), Stderr(io.Discard))
+		app.Add("test", testCommand, nil)
+		args := append([]string{"appname"}, strings.Split(line, " ")...)
+		err := app.Run(args)
+		if !reflect.DeepEqual(err, want) {
+			t.Fatalf("error for '%s'\nhave %v\nwant %v", line, err, want)
+		}
+		if err != nil {
+			continue
+		}
+		if color || !f.IsSet() {
+			t.Fatalf("color should be set to false for '%s'", line)
+		}
+	}
+}
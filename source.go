@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// debugFlagName is the name of the built in flag that prints
+// the effective flag values and their sources before dispatch.
+const debugFlagName = "debug-flags"
+
+// SourceKind represents where a flag value came from.
+type SourceKind int
+
+// Flag value sources in order of increasing precedence.
+const (
+	SourceNone SourceKind = iota
+	SourceDefault
+	SourceConfig
+	SourceEnv
+	SourceCommandLine
+)
+
+// String implements the fmt.Stringer interface.
+func (k SourceKind) String() string {
+	switch k {
+	case SourceDefault:
+		return "default"
+	case SourceConfig:
+		return "config"
+	case SourceEnv:
+		return "env"
+	case SourceCommandLine:
+		return "command line"
+	}
+	return "none"
+}
+
+// Source represents the origin of a flag value.
+type Source struct {
+	// Kind is the kind of source that last set the flag.
+	Kind SourceKind
+
+	// Name is the environment variable key or the configuration
+	// file path. It is empty for other kinds of source.
+	Name string
+
+	// Value is the raw value as given by the source. Values of
+	// accumulating flag kinds are joined by the flag separator.
+	Value string
+}
+
+// String implements the fmt.Stringer interface.
+func (s Source) String() string {
+	if s.Name == "" {
+		return s.Kind.String()
+	}
+	return fmt.Sprintf("%s %s", s.Kind, s.Name)
+}
+
+// writeSources writes a table of the effective value
+// and source of each flag to w.
+func writeSources(w io.Writer, flags []*Flag) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "FLAG\tVALUE\tSOURCE")
+	for _, f := range flags {
+		fmt.Fprintf(tw, "--%s\t%s\t%s\n", f.name, f.effective(), f.source)
+	}
+	return tw.Flush()
+}
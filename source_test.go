@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestFlagSource(t *testing.T) {
+	path := writeTestConfig(t, "config", "host = config\n")
+	t.Setenv("TEST_CONFIG_PORT", "2")
+	c := &testConfigFile{}
+	app := newTestConfigApp(t, c, ConfigFile("config"))
+	err := app.Run([]string{"appname", "--config", path, "deploy", "--tag", "a", "--tag", "b"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tests := []struct {
+		name string
+		want Source
+	}{
+		{"host", Source{Kind: SourceConfig, Name: path, Value: "config"}},
+		{"port", Source{Kind: SourceEnv, Name: "TEST_CONFIG_PORT", Value: "2"}},
+		{"verbose", Source{}},
+		{"tag", Source{Kind: SourceCommandLine, Value: "a,b"}},
+		{"config", Source{Kind: SourceCommandLine, Value: path}},
+	}
+	for _, tt := range tests {
+		have := app.flagsMap[tt.name].Source()
+		if have != tt.want {
+			t.Errorf("%s\nhave %+v\nwant %+v", tt.name, have, tt.want)
+		}
+	}
+}
+
+func TestFlagSourceDefault(t *testing.T) {
+	var port int
+	f := NewFlag("port", &port, DefaultValue("80"))
+	want := Source{Kind: SourceDefault, Value: "80"}
+	if f.Source() != want {
+		t.Fatalf("source\nhave %+v\nwant %+v", f.Source(), want)
+	}
+	if f.Source().String() != "default" {
+		t.Fatalf("string\nhave %q\nwant %q", f.Source().String(), "default")
+	}
+}
+
+func TestRunDebugFlags(t *testing.T) {
+	t.Setenv("TEST_CONFIG_PORT", "2")
+	var stderr bytes.Buffer
+	c := &testConfigFile{}
+	app := newTestConfigApp(t, c, DebugFlags(), Stderr(&stderr))
+	err := app.Run([]string{"appname", "--debug-flags", "deploy", "--tag", "a"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{
+		"FLAG           VALUE      SOURCE",
+		"--host         localhost  default",
+		"--port         2          env TEST_CONFIG_PORT",
+		"--verbose      false      none",
+		"--debug-flags  true       command line",
+		"--dry-run      false      none",
+		"--tag          [a]        command line",
+		"--label        map[]      none",
+	}
+	have := strings.Split(strings.TrimSuffix(stderr.String(), "\n"), "\n")
+	if strings.Join(have, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("output\nhave\n%s\nwant\n%s", stderr.String(), strings.Join(want, "\n"))
+	}
+}
+
+func TestRunDebugFlagsDefault(t *testing.T) {
+	var stderr bytes.Buffer
+	c := &testConfigFile{}
+	app := newTestConfigApp(t, c, DebugFlags(), Default(testCommand), Stderr(&stderr))
+	err := app.Run([]string{"appname", "--debug-flags"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "FLAG           VALUE      SOURCE\n"
+	if !strings.HasPrefix(stderr.String(), want) {
+		t.Fatalf("output\nhave\n%s\nwant prefix\n%s", stderr.String(), want)
+	}
+}
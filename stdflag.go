@@ -0,0 +1,30 @@
+package cli
+
+import "flag"
+
+// ValueKind returns a flag kind that sets v by calling its Set method.
+// Values implementing an IsBoolFlag method that returns true do not
+// require an argument, as with the standard library flag package.
+//
+// If v is a pointer, the flag must point to the same value:
+//
+//	NewFlag("level", &level, KindE(ValueKind(&level)))
+func ValueKind(v flag.Value) FlagKindE {
+	return newFlagValue(v)
+}
+
+// FlagsFromSet returns flags for every flag defined in fs. Flag names,
+// usage text and default values are carried over. Values set on the
+// command line are set on the underlying flag.Value so the options
+// registered on fs are updated in place.
+func FlagsFromSet(fs *flag.FlagSet) []*Flag {
+	var flags []*Flag
+	fs.VisitAll(func(sf *flag.Flag) {
+		f := NewFlag(sf.Name, sf.Value, Usage(sf.Usage))
+		f.defaultValue = sf.DefValue
+		f.value = sf.DefValue
+		f.source = Source{Kind: SourceDefault, Value: sf.DefValue}
+		flags = append(flags, f)
+	})
+	return flags
+}
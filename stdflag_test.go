@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"flag"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFlagsFromSet(t *testing.T) {
+	var called string
+	fs := flag.NewFlagSet("lib", flag.ContinueOnError)
+	name := fs.String("name", "x", "The name.")
+	verbose := fs.Bool("verbose", false, "Verbose output.")
+	timeout := fs.Duration("timeout", time.Second, "The timeout.")
+	fs.Func("hook", "The hook.", func(value string) error {
+		called = value
+		return nil
+	})
+	flags := FlagsFromSet(fs)
+	if len(flags) != 4 {
+		t.Fatalf("should convert every flag, have %d", len(flags))
+	}
+	for _, f := range flags {
+		if f.name == "name" && (f.defaultValue != "x" || f.usage != "The name.") {
+			t.Fatalf("should carry over default and usage: %+v", f)
+		}
+	}
+	app := New("appname", newTestUsage(t), nil, Stderr(io.Discard))
+	app.Add("test", testCommand, flags)
+	err := app.Run([]string{"appname", "test", "--name", "y", "--verbose", "--timeout=1m", "--hook", "h"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *name != "y" || !*verbose || *timeout != time.Minute || called != "h" {
+		t.Fatalf("flag set not updated: %v %v %v %v", *name, *verbose, *timeout, called)
+	}
+}
+
+func TestValueKind(t *testing.T) {
+	var lvl testLevel
+	f := NewFlag("level", &lvl, KindE(ValueKind(&lvl)))
+	err := f.Set("abc")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if lvl != 3 || f.String() != "abc" {
+		t.Fatalf("level\nhave %d\nwant %d", lvl, 3)
+	}
+}
+
+type testList []string
+
+func (l *testList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *testList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func TestFlagsFromSetRepeated(t *testing.T) {
+	fs := flag.NewFlagSet("lib", flag.ContinueOnError)
+	list := &testList{"a"}
+	fs.Var(list, "item", "The items.")
+	app := New("appname", newTestUsage(t), nil, Stderr(io.Discard))
+	app.Add("test", testCommand, FlagsFromSet(fs))
+	var tests = []struct {
+		args []string
+		want testList
+	}{
+		{[]string{"appname", "test"}, testList{"a"}},
+		{[]string{"appname", "test", "--item", "b"}, testList{"a", "b"}},
+		{[]string{"appname", "test"}, testList{"a"}},
+	}
+	for i, tt := range tests {
+		err := app.Run(tt.args)
+		if err != nil {
+			t.Fatalf("%d: unexpected error: %v", i, err)
+		}
+		if !reflect.DeepEqual(*list, tt.want) {
+			t.Fatalf("%d: items\nhave %v\nwant %v", i, *list, tt.want)
+		}
+	}
+}
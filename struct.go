@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// FlagsFrom returns flags for the exported fields of the struct that v
+// points to. The flag kind is inferred from the field type. Fields of
+// nested structs are prefixed with the name of the struct field and
+// embedded structs are flattened.
+//
+// Flags are configured with the "cli" struct tag. The first element
+// is the flag name, which defaults to the field name in lower case
+// with words separated by hyphens. A name of "-" skips the field.
+// The remaining comma separated elements are options:
+//
+//	short=n      sets the short flag
+//	default=x    sets the default value
+//	env=FOO      sets the environment variable key
+//	sep=;        sets the separator for slice and map flags
+//	oneof=a|b    restricts the flag to the allowed values
+//	required     requires the flag
+//	negatable    allows a boolean flag to be negated
+//	usage=...    sets the flag usage text, must be the last option
+//
+// For example:
+//
+//	type Config struct {
+//		Name    string        `cli:"name,short=n,default=x,env=FOO,usage=The name."`
+//		Timeout time.Duration `cli:",default=30s"`
+//		DryRun  bool          `cli:",negatable"`
+//	}
+//
+// FlagsFrom panics if v is not a pointer to a struct, a tag is
+// malformed or a field type has no built in flag kind.
+func FlagsFrom(v interface{}) []*Flag {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		panic("cli: flags must be pointer to struct")
+	}
+	return flagsFrom(rv.Elem(), "")
+}
+
+// flagsFrom returns flags for the fields of the struct value v.
+func flagsFrom(v reflect.Value, prefix string) []*Flag {
+	var flags []*Flag
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		tag := field.Tag.Get("cli")
+		if tag == "-" {
+			continue
+		}
+		name := tag
+		opts := ""
+		j := strings.Index(tag, ",")
+		if j != -1 {
+			name, opts = tag[:j], tag[j+1:]
+		}
+		if name == "" {
+			name = kebab(field.Name)
+		}
+		fv := v.Field(i)
+		if kindOf(fv) == nil && field.Type.Kind() == reflect.Struct {
+			if field.Anonymous && tag == "" {
+				flags = append(flags, flagsFrom(fv, prefix)...)
+			} else {
+				flags = append(flags, flagsFrom(fv, prefix+name+"-")...)
+			}
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		options := tagOptions(field.Name, opts)
+		flags = append(flags, NewFlag(prefix+name, fv.Addr().Interface(), options...))
+	}
+	return flags
+}
+
+// tagOptions returns the flag options for the struct tag options.
+func tagOptions(field, tag string) []FlagOption {
+	var opts []FlagOption
+	for tag != "" {
+		opt := tag
+		i := strings.Index(tag, ",")
+		if i != -1 && !strings.HasPrefix(tag, "usage=") {
+			opt, tag = tag[:i], tag[i+1:]
+		} else {
+			tag = ""
+		}
+		key, value := opt, ""
+		j := strings.Index(opt, "=")
+		if j != -1 {
+			key, value = opt[:j], opt[j+1:]
+		}
+		switch key {
+		case "short":
+			opts = append(opts, ShortFlag(value))
+		case "default":
+			opts = append(opts, DefaultValue(value))
+		case "env":
+			opts = append(opts, EnvironmentKey(value))
+		case "sep":
+			opts = append(opts, Separator(value))
+		case "oneof":
+			opts = append(opts, OneOf(strings.Split(value, "|")...))
+		case "required":
+			opts = append(opts, Required())
+		case "negatable":
+			opts = append(opts, Negatable())
+		case "usage":
+			opts = append(opts, Usage(value))
+		default:
+			panic(fmt.Errorf("cli: unknown tag option '%s' for field '%s'", key, field))
+		}
+	}
+	return opts
+}
+
+// kebab returns the field name in lower case with words separated
+// by hyphens. For example, "DryRun" returns "dry-run" and "HTTPPort"
+// returns "http-port".
+func kebab(name string) string {
+	var b strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			next := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && next) {
+				b.WriteByte('-')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
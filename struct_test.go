@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testDatabase struct {
+	Host string `cli:",default=localhost"`
+	Port int    `cli:",default=5432"`
+}
+
+type testEmbedded struct {
+	Verbose bool `cli:",short=v"`
+}
+
+type testConfig struct {
+	testEmbedded
+	Name     string            `cli:"name,short=n,default=x,env=FOO,usage=The name, or alias."`
+	Timeout  time.Duration     `cli:",default=30s"`
+	DryRun   bool              `cli:",negatable"`
+	Format   string            `cli:",oneof=json|yaml,required"`
+	Tags     []string          `cli:"tag,sep=;"`
+	Labels   map[string]string `cli:"label"`
+	Database testDatabase
+	Skipped  string `cli:"-"`
+	private  string
+}
+
+func TestFlagsFrom(t *testing.T) {
+	cfg := &testConfig{}
+	flags := FlagsFrom(cfg)
+	names := make([]string, len(flags))
+	for i, f := range flags {
+		names[i] = f.name
+	}
+	want := []string{"verbose", "name", "timeout", "dry-run", "format", "tag", "label", "database-host", "database-port"}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("names\nhave %v\nwant %v", names, want)
+	}
+	name := flags[1]
+	if name.alias != "n" || name.envKey != "FOO" || name.usage != "The name, or alias." {
+		t.Fatalf("name flag options not applied: %+v", name)
+	}
+	if !flags[3].negatable || !flags[4].required || flags[5].sep != ";" {
+		t.Fatal("flag options not applied")
+	}
+	args := []string{"-v", "--name", "y", "--no-dry-run", "--format", "yaml", "--tag", "a;b", "--label", "k=v", "--database-port", "1"}
+	_, err := Parse(args, flags)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wantCfg := &testConfig{
+		testEmbedded: testEmbedded{Verbose: true},
+		Name:         "y",
+		Timeout:      30 * time.Second,
+		Format:       "yaml",
+		Tags:         []string{"a;b"},
+		Labels:       map[string]string{"k": "v"},
+		Database:     testDatabase{Host: "localhost", Port: 1},
+	}
+	if !reflect.DeepEqual(cfg, wantCfg) {
+		t.Fatalf("config\nhave %+v\nwant %+v", cfg, wantCfg)
+	}
+}
+
+func TestFlagsFromPanic(t *testing.T) {
+	tests := []interface{}{
+		testConfig{},
+		&struct {
+			C chan int
+		}{},
+		&struct {
+			S string `cli:",unknown"`
+		}{},
+	}
+	for _, v := range tests {
+		func() {
+			defer func() {
+				perr := recover()
+				if perr == nil {
+					t.Fatalf("FlagsFrom(%T) should panic", v)
+				}
+			}()
+			FlagsFrom(v)
+		}()
+	}
+}
+
+func TestKebab(t *testing.T) {
+	tests := map[string]string{
+		"Name":     "name",
+		"DryRun":   "dry-run",
+		"HTTPPort": "http-port",
+	}
+	for name, want := range tests {
+		have := kebab(name)
+		if have != want {
+			t.Fatalf("kebab(%q)\nhave %s\nwant %s", name, have, want)
+		}
+	}
+}
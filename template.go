@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+)
+
+// UsageData represents the data available to usage templates.
+type UsageData struct {
+	// Name is the application name.
+	Name string
+
+	// Version is the application version.
+	Version string
+
+	// Command is the slash separated command path.
+	// It is empty for the application help topic.
+	Command string
+
+	// Flags are the flags of the command or the
+	// application flags for the application topic.
+	Flags []*Flag
+
+	// GlobalFlags are the application flags and the flags of
+	// every parent command. It is empty for the application topic.
+	GlobalFlags []*Flag
+}
+
+// Flag returns the command or global flag with the given name.
+// An error is returned if no such flag exists so that templates
+// referring to removed flags fail rather than going stale.
+func (d UsageData) Flag(name string) (*Flag, error) {
+	for _, flags := range [][]*Flag{d.Flags, d.GlobalFlags} {
+		for _, f := range flags {
+			if f.name == name {
+				return f, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("flag '%s' is undefined", name)
+}
+
+// usageData returns the usage template data for the command.
+func (c *CLI) usageData(cmd *Command) UsageData {
+	d := UsageData{
+		Name:    c.name,
+		Version: c.version,
+		Flags:   c.flags,
+	}
+	if cmd != nil {
+		d.Command = cmd.path
+		d.Flags = cmd.flags
+		d.GlobalFlags = c.parentFlags(cmd)
+	}
+	return d
+}
+
+// executeUsage executes the usage file b as a template.
+func (c *CLI) executeUsage(name string, b []byte, cmd *Command) ([]byte, error) {
+	funcs := template.FuncMap{
+		"env": c.flagEnvKey,
+		"default": func(f *Flag) string {
+			return f.defaultValue
+		},
+		"alias": func(f *Flag) string {
+			return f.alias
+		},
+	}
+	t, err := template.New(name).Funcs(funcs).Parse(string(b))
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	err = t.Execute(&buf, c.usageData(cmd))
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"testing/fstest"
+)
+
+func TestTemplateUsage(t *testing.T) {
+	usage := NewUsageFS(fstest.MapFS{
+		"README.md": {Data: []byte("{{.Name}} {{.Version}}\n")},
+		"serve.md": {Data: []byte(`{{.Command}} on {{.Flag "port" | default}}
+-{{.Flag "port" | alias}} or ${{.Flag "port" | env}}
+{{range .GlobalFlags}}{{index .Forms 0}}{{end}}
+`)},
+		"broken.md": {Data: []byte(`{{.Flag "missing" | env}}`)},
+	})
+	var port int
+	var verbose bool
+	flags := []*Flag{NewFlag("verbose", &verbose)}
+	var buf bytes.Buffer
+	opts := []Option{TemplateUsage(), Version("1.0.0"), Stdout(&buf), Stderr(io.Discard)}
+	app := New("appname", usage, flags, opts...)
+	app.Add("serve", testCommand, []*Flag{
+		NewFlag("port", &port, ShortFlag("p"), DefaultValue("8080")),
+	})
+	app.Add("broken", testCommand, nil)
+	var tests = []struct {
+		args []string
+		want string
+	}{
+		{[]string{"appname", "help"}, "appname 1.0.0\n"},
+		{[]string{"appname", "help", "serve"}, "serve on 8080\n-p or $APPNAME_PORT\n--verbose\n"},
+	}
+	for _, tt := range tests {
+		buf.Reset()
+		err := app.Run(tt.args)
+		if err != nil {
+			t.Fatalf("help %v\nunexpected error: %v", tt.args, err)
+		}
+		have := buf.String()
+		if have != tt.want {
+			t.Fatalf("help %v\nhave '%s'\nwant '%s'", tt.args, have, tt.want)
+		}
+	}
+	err := app.Usage(io.Discard, "broken")
+	if err == nil {
+		t.Fatalf("undefined template flag should error")
+	}
+}
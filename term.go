@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"io"
+	"os"
+	"strconv"
+)
+
+// defaultWidth is the terminal width used when
+// the width cannot be determined.
+const defaultWidth = 80
+
+// isTerminal returns true if w is a terminal.
+func isTerminal(w io.Writer) bool {
+	_, ok := terminalFile(w)
+	return ok
+}
+
+// terminalFile returns the terminal that w writes to. Output
+// buffered for the pager is considered to be written to the
+// terminal the pager writes to.
+func terminalFile(w io.Writer) (*os.File, bool) {
+	var f *os.File
+	switch w := w.(type) {
+	case *os.File:
+		f = w
+	case *pagerBuffer:
+		f = w.file
+	default:
+		return nil, false
+	}
+	fi, err := f.Stat()
+	if err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+		return nil, false
+	}
+	return f, true
+}
+
+// terminalWidth returns the width of the terminal w. The COLUMNS
+// environment variable is used if w is not a terminal or its size
+// cannot be determined, falling back to 80 columns.
+func (c *CLI) terminalWidth(w io.Writer) int {
+	f, ok := terminalFile(w)
+	if ok {
+		cols, _, ok := terminalSize(f)
+		if ok {
+			return cols
+		}
+	}
+	cols, err := strconv.Atoi(c.getenv("COLUMNS"))
+	if err == nil && cols > 0 {
+		return cols
+	}
+	return defaultWidth
+}
@@ -0,0 +1,12 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package cli
+
+import "os"
+
+// terminalSize returns false as the terminal size
+// cannot be determined on this platform.
+func terminalSize(f *os.File) (int, int, bool) {
+	return 0, 0, false
+}
@@ -2,8 +2,10 @@ package cli
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"io/fs"
+	"path"
 )
 
 // nilUsage represents the nil usage.
@@ -17,9 +19,10 @@ func (u *nilUsage) Open(name string) (fs.File, error) {
 // UsageFS is a io/fs.FS implementation that
 // reads files from usage lookup keys.
 type UsageFS struct {
-	fs    fs.FS
-	ext   string
-	index string
+	fs      fs.FS
+	ext     string
+	index   string
+	locales []string
 }
 
 // NewUsageFS returns a usage lookup fs.FS implementation.
@@ -47,6 +50,15 @@ func (u *UsageFS) Open(name string) (fs.File, error) {
 	} else {
 		name += u.ext
 	}
+	for _, locale := range u.locales {
+		f, err := u.fs.Open(path.Join(locale, name))
+		if err == nil {
+			return f, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
 	return u.fs.Open(name)
 }
 
@@ -58,11 +70,26 @@ func (u *UsageFS) Open(name string) (fs.File, error) {
 // is "cli" and the "foo" command is registered, "help foo"
 // will call the renderer with "cli/foo" but "help not-found"
 // would passthrough as "not-found" without the scope.
+//
+// Subcommand help topics are the slash separated command
+// path. For example, "help cluster node" will call the
+// renderer with "cli/cluster/node".
+//
+// Usage files are executed as text/template templates if the
+// application is configured with the TemplateUsage option and
+// rendered for the terminal with the MarkdownUsage option.
+//
+// If the usage FS has no file for the application or a registered
+// command, a help page is generated from the application, command
+// and flag metadata. Usage files always take precedence.
+//
+// Flag constraints declared on the command, such as mutually
+// exclusive flags, are appended to the command help topic.
 func (c *CLI) Usage(w io.Writer, name string) error {
 	key := name
-	_, ok := c.commands[name]
-	if ok {
-		key = c.scope + name
+	cmd := c.lookup(name)
+	if cmd != nil {
+		key = c.scope + cmd.path
 	}
 	b, err := fs.ReadFile(c.usage, key)
 	if err != nil {
@@ -70,14 +97,55 @@ func (c *CLI) Usage(w io.Writer, name string) error {
 		if !errors.As(err, &perr) {
 			return err
 		}
-		if name == "" || name == c.scope {
-			c.Errorf("Unknown help topic.\n")
-		} else {
-			c.Errorf("Unknown help topic '%s'.\n", name)
+		if cmd == nil && name != "" && name != c.scope {
+			c.errorln("Unknown help topic '%s'.", name)
+			c.errorln("Run '%s help' for usage information.", c.name)
+			return ErrExitFailure
+		}
+		err = c.writeHelp(w, cmd)
+	} else {
+		err = c.writeUsage(w, key, b, cmd)
+	}
+	if err != nil {
+		return err
+	}
+	return c.writeGroups(w, cmd)
+}
+
+// writeUsage writes the usage file b to w. The file is executed as a
+// template and rendered as markdown if the application is configured
+// to do so. Markdown is styled with ANSI escape sequences if w is a
+// terminal and is written as plain text otherwise.
+func (c *CLI) writeUsage(w io.Writer, key string, b []byte, cmd *Command) error {
+	var err error
+	if c.templateUsage {
+		b, err = c.executeUsage(key, b, cmd)
+		if err != nil {
+			return err
 		}
-		c.Errorf("Run '%s help' for usage information.\n", c.name)
-		return ErrExitFailure
+	}
+	if c.markdownUsage {
+		m := &markdown{width: c.terminalWidth(w), ansi: isTerminal(w)}
+		b = m.render(b)
 	}
 	_, err = w.Write(b)
 	return err
 }
+
+// writeGroups writes the flag constraints of the command to w.
+func (c *CLI) writeGroups(w io.Writer, cmd *Command) error {
+	if cmd == nil || len(cmd.groups) == 0 {
+		return nil
+	}
+	_, err := fmt.Fprintf(w, "\n%s\n\n", c.catalog.sprintf("Flag constraints:"))
+	if err != nil {
+		return err
+	}
+	for _, g := range cmd.groups {
+		_, err = fmt.Fprintf(w, "    %s\n", g.message(c.catalog))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
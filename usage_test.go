@@ -46,12 +46,17 @@ func TestUsage(t *testing.T) {
 			[]string{"appname", "help", "test"},
 			"cli/test.md\n",
 		},
+		{
+			"",
+			[]string{"appname", "help", "test", "sub"},
+			"test/sub.md\n",
+		},
 	}
 	for _, tt := range tests {
 		var buf bytes.Buffer
 		opts := []Option{Scope(tt.scope), Stdout(&buf), Stderr(io.Discard)}
 		app := New("appname", newTestUsage(t), nil, opts...)
-		app.Add("test", testCommand, nil)
+		app.Add("test", testCommand, nil).Add("sub", testCommand, nil)
 		err := app.Run(tt.args)
 		if err != nil {
 			t.Fatalf("help args=%v scope='%s'\ncommand should not error", tt.args, tt.scope)